@@ -0,0 +1,77 @@
+package remote
+
+import (
+	"github.com/spf13/cobra"
+
+	"bunnyshell.com/dev/pkg/config"
+	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/remote"
+)
+
+func init() {
+	var (
+		deploymentName  string
+		statefulSetName string
+		daemonSetName   string
+		jobName         string
+		cronJobName     string
+		resourceName    string
+
+		clone bool
+	)
+
+	command := &cobra.Command{
+		Use:   "diff",
+		Short: "Show the changes of the remote development session to the resource",
+		RunE: func(command *cobra.Command, _ []string) error {
+			devConfig, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			kubeConfigOptions := k8s.GetKubeConfigOptions()
+
+			remoteDevelopment := remote.NewRemoteDevelopment()
+			remoteDevelopment.
+				WithContext(command.Context()).
+				WithKubeConfigOptions(kubeConfigOptions).
+				WithConfig(devConfig).
+				WithClone(clone)
+
+			// input
+			if err := selectNamespace(remoteDevelopment, kubeConfigOptions); err != nil {
+				return err
+			}
+
+			if deploymentName != "" {
+				remoteDevelopment.WithDeploymentName(deploymentName)
+			} else if statefulSetName != "" {
+				remoteDevelopment.WithStatefulSetName(statefulSetName)
+			} else if daemonSetName != "" {
+				remoteDevelopment.WithDaemonSetName(daemonSetName)
+			} else if jobName != "" {
+				remoteDevelopment.WithJobName(jobName)
+			} else if cronJobName != "" {
+				remoteDevelopment.WithCronJobName(cronJobName)
+			} else if resourceName != "" {
+				remoteDevelopment.WithCustomResourceName(resourceName)
+			} else {
+				if err := remoteDevelopment.SelectResource(); err != nil {
+					return err
+				}
+			}
+
+			return remoteDevelopment.Diff()
+		},
+	}
+
+	command.Flags().StringVarP(&deploymentName, "deployment", "d", "", "Kubernetes Deployment")
+	command.Flags().StringVarP(&statefulSetName, "statefulset", "s", "", "Kubernetes StatefulSet")
+	command.Flags().StringVarP(&daemonSetName, "daemonset", "t", "", "Kubernetes DaemonSet")
+	command.Flags().StringVar(&jobName, "job", "", "Kubernetes Job, developed on a copy")
+	command.Flags().StringVar(&cronJobName, "cronjob", "", "Kubernetes CronJob, developed on a Job created from it")
+	command.Flags().StringVar(&resourceName, "resource", "", "Custom workload as <kind>/<name>, e.g. rollout/api")
+	command.Flags().BoolVar(&clone, "clone", false, "Show the changes of the remote development copy of the resource")
+
+	mainCmd.AddCommand(command)
+}
@@ -0,0 +1,70 @@
+package remote
+
+import (
+	"github.com/spf13/cobra"
+
+	"bunnyshell.com/dev/pkg/config"
+	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/remote"
+)
+
+func init() {
+	var (
+		deploymentName  string
+		statefulSetName string
+		daemonSetName   string
+		jobName         string
+		cronJobName     string
+		resourceName    string
+
+		clone       bool
+		pin         bool
+		pauseGitOps bool
+	)
+
+	command := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the environment and the cluster access needed by remote development",
+		RunE: func(command *cobra.Command, _ []string) error {
+			devConfig, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			doctor := remote.NewDoctor(k8s.GetKubeConfigOptions()).
+				WithContext(command.Context()).
+				WithConfig(devConfig).
+				WithClone(clone).
+				WithPin(pin).
+				WithPauseGitOps(pauseGitOps)
+
+			if deploymentName != "" {
+				doctor.WithResource(remote.Deployment, deploymentName)
+			} else if statefulSetName != "" {
+				doctor.WithResource(remote.StatefulSet, statefulSetName)
+			} else if daemonSetName != "" {
+				doctor.WithResource(remote.DaemonSet, daemonSetName)
+			} else if jobName != "" {
+				doctor.WithResource(remote.Job, jobName)
+			} else if cronJobName != "" {
+				doctor.WithResource(remote.CronJob, cronJobName)
+			} else if resourceName != "" {
+				doctor.WithCustomResourceName(resourceName)
+			}
+
+			return doctor.Run()
+		},
+	}
+
+	command.Flags().StringVarP(&deploymentName, "deployment", "d", "", "Kubernetes Deployment")
+	command.Flags().StringVarP(&statefulSetName, "statefulset", "s", "", "Kubernetes StatefulSet")
+	command.Flags().StringVarP(&daemonSetName, "daemonset", "t", "", "Kubernetes DaemonSet")
+	command.Flags().StringVar(&jobName, "job", "", "Kubernetes Job")
+	command.Flags().StringVar(&cronJobName, "cronjob", "", "Kubernetes CronJob")
+	command.Flags().StringVar(&resourceName, "resource", "", "Custom workload as <kind>/<name>, e.g. rollout/api")
+	command.Flags().BoolVar(&clone, "clone", false, "Check the access needed to develop on a copy of the resource")
+	command.Flags().BoolVar(&pin, "pin", false, "Check the access needed to pin the session to a DaemonSet node or a StatefulSet ordinal")
+	command.Flags().BoolVar(&pauseGitOps, "pause-gitops", false, "Check the access needed to pause Argo CD / Flux reconciliation")
+
+	mainCmd.AddCommand(command)
+}
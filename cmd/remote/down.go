@@ -2,29 +2,69 @@ package remote
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/thediveo/enumflag/v2"
 
+	"bunnyshell.com/dev/pkg/config"
 	"bunnyshell.com/dev/pkg/k8s"
 	"bunnyshell.com/dev/pkg/remote"
 )
 
+// +enum
+type restoreStrategy enumflag.Flag
+
+const (
+	restoreAuto restoreStrategy = iota
+	restoreSnapshot
+	restoreUpstream
+)
+
+var restoreStrategyIds = map[restoreStrategy][]string{
+	restoreAuto:     {string(remote.RestoreStrategyAuto)},
+	restoreSnapshot: {string(remote.RestoreStrategySnapshot)},
+	restoreUpstream: {string(remote.RestoreStrategyUpstream)},
+}
+
+var restoreStrategyToRemoteStrategy = map[restoreStrategy]remote.RestoreStrategy{
+	restoreAuto:     remote.RestoreStrategyAuto,
+	restoreSnapshot: remote.RestoreStrategySnapshot,
+	restoreUpstream: remote.RestoreStrategyUpstream,
+}
+
 func init() {
 	var (
-		namespaceName   string
 		deploymentName  string
 		statefulSetName string
 		daemonSetName   string
+		jobName         string
+		cronJobName     string
+		resourceName    string
+
+		restoreStrategy restoreStrategy = restoreAuto
+		clone           bool
+
+		dryRun dryRun = dryRunNone
 	)
 
 	command := &cobra.Command{
 		Use: "down",
-		RunE: func(_ *cobra.Command, _ []string) error {
+		RunE: func(command *cobra.Command, _ []string) error {
+			devConfig, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			kubeConfigOptions := k8s.GetKubeConfigOptions()
+
 			remoteDevelopment := remote.NewRemoteDevelopment()
-			remoteDevelopment.WithKubernetesClient(k8s.GetKubeConfigFilePath())
+			remoteDevelopment.
+				WithContext(command.Context()).
+				WithKubeConfigOptions(kubeConfigOptions).
+				WithConfig(devConfig).
+				WithRestoreStrategy(restoreStrategyToRemoteStrategy[restoreStrategy]).
+				WithClone(clone)
 
 			// input
-			if namespaceName != "" {
-				remoteDevelopment.WithNamespaceName(namespaceName)
-			} else if err := remoteDevelopment.SelectNamespace(); err != nil {
+			if err := selectNamespace(remoteDevelopment, kubeConfigOptions); err != nil {
 				return err
 			}
 
@@ -34,20 +74,41 @@ func init() {
 				remoteDevelopment.WithStatefulSetName(statefulSetName)
 			} else if daemonSetName != "" {
 				remoteDevelopment.WithDaemonSetName(daemonSetName)
+			} else if jobName != "" {
+				remoteDevelopment.WithJobName(jobName)
+			} else if cronJobName != "" {
+				remoteDevelopment.WithCronJobName(cronJobName)
+			} else if resourceName != "" {
+				remoteDevelopment.WithCustomResourceName(resourceName)
 			} else {
 				if err := remoteDevelopment.SelectResource(); err != nil {
 					return err
 				}
 			}
 
+			if dryRun != dryRunNone {
+				return remoteDevelopment.
+					WithDryRun(dryRunToRemoteDryRun[dryRun]).
+					DryRunDown()
+			}
+
 			return remoteDevelopment.Down()
 		},
 	}
 
-	command.Flags().StringVarP(&namespaceName, "namespace", "n", "", "Kubernetes Namespace")
 	command.Flags().StringVarP(&deploymentName, "deployment", "d", "", "Kubernetes Deployment")
 	command.Flags().StringVarP(&statefulSetName, "statefulset", "s", "", "Kubernetes StatefulSet")
 	command.Flags().StringVarP(&daemonSetName, "daemonset", "t", "", "Kubernetes DaemonSet")
+	command.Flags().StringVar(&jobName, "job", "", "Kubernetes Job, developed on a copy")
+	command.Flags().StringVar(&cronJobName, "cronjob", "", "Kubernetes CronJob, suspended while developing on a Job created from it")
+	command.Flags().StringVar(&resourceName, "resource", "", "Custom workload as <kind>/<name>, e.g. rollout/api")
+	command.Flags().BoolVar(&clone, "clone", false, "Delete the remote development copy of the resource")
+	command.Flags().Var(
+		enumflag.New(&restoreStrategy, "restore", restoreStrategyIds, enumflag.EnumCaseSensitive),
+		"restore",
+		"How to restore a resource updated upstream during the session.\nAvailable strategies: auto, snapshot, upstream.\n\"auto\" asks when upstream changes are detected.",
+	)
+	addDryRunFlag(command, &dryRun)
 
 	mainCmd.AddCommand(command)
 }
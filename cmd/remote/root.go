@@ -1,9 +1,37 @@
 package remote
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
+	"github.com/thediveo/enumflag/v2"
+
+	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/remote"
+)
+
+// +enum
+type dryRun enumflag.Flag
+
+const (
+	dryRunNone dryRun = iota
+	dryRunClient
+	dryRunServer
 )
 
+var dryRunIds = map[dryRun][]string{
+	dryRunNone:   {string(remote.DryRunNone)},
+	dryRunClient: {string(remote.DryRunClient)},
+	dryRunServer: {string(remote.DryRunServer)},
+}
+
+var dryRunToRemoteDryRun = map[dryRun]remote.DryRun{
+	dryRunNone:   remote.DryRunNone,
+	dryRunClient: remote.DryRunClient,
+	dryRunServer: remote.DryRunServer,
+}
+
 var mainCmd = &cobra.Command{
 	Use:   "remote",
 	Short: "Remote Development",
@@ -12,3 +40,36 @@ var mainCmd = &cobra.Command{
 func GetMainCommand() *cobra.Command {
 	return mainCmd
 }
+
+func addDryRunFlag(command *cobra.Command, value *dryRun) {
+	command.Flags().Var(
+		enumflag.New(value, "dry-run", dryRunIds, enumflag.EnumCaseSensitive),
+		"dry-run",
+		"Print the changes instead of making them.\nAvailable modes: none, client, server.\n\"server\" also sends them to the API server for validation.",
+	)
+	command.Flags().Lookup("dry-run").NoOptDefVal = string(remote.DryRunClient)
+}
+
+// selectNamespace uses the --namespace flag, then the namespace set explicitly on the kubeconfig context;
+// without either, the namespace is selected interactively
+func selectNamespace(remoteDevelopment *remote.RemoteDevelopment, kubeConfigOptions *k8s.KubeConfigOptions) error {
+	if kubeConfigOptions.Namespace != "" {
+		remoteDevelopment.WithNamespaceName(kubeConfigOptions.Namespace)
+		return nil
+	}
+
+	namespaceName, err := kubeConfigOptions.GetContextNamespace()
+	if err != nil {
+		return err
+	}
+
+	if namespaceName == "" {
+		return remoteDevelopment.SelectNamespace()
+	}
+
+	// stderr keeps the dry run output a valid YAML stream
+	fmt.Fprintf(os.Stderr, "Using namespace %s of the kubeconfig context, set --namespace to use another one\n", namespaceName)
+	remoteDevelopment.WithNamespaceName(namespaceName)
+
+	return nil
+}
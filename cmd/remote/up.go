@@ -4,6 +4,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/thediveo/enumflag/v2"
 
+	"bunnyshell.com/dev/pkg/config"
 	"bunnyshell.com/dev/pkg/k8s"
 	mutagenConfig "bunnyshell.com/dev/pkg/mutagen/config"
 	"bunnyshell.com/dev/pkg/remote"
@@ -38,11 +39,16 @@ var syncModeToMutagenMode = map[syncMode]mutagenConfig.Mode{
 
 func init() {
 	var (
-		namespaceName   string
 		deploymentName  string
 		statefulSetName string
 		daemonSetName   string
+		jobName         string
+		cronJobName     string
+		resourceName    string
 		containerName   string
+		nodeName        string
+		ordinal         int
+		pin             bool
 
 		syncMode       syncMode = twoWayResolved
 		localSyncPath  string
@@ -52,21 +58,73 @@ func init() {
 
 		waitTimeout int
 		noTTY       bool
+		pauseGitOps bool
+
+		forceConflicts bool
+		keepOnFailure  bool
+
+		devImage            string
+		imageRegistry       string
+		binariesImage       string
+		binariesImageDigest string
+		imagePullSecrets    []string
+
+		clone        bool
+		cloneTraffic bool
+
+		dryRun dryRun = dryRunNone
 	)
 
 	command := &cobra.Command{
 		Use: "up",
-		RunE: func(_ *cobra.Command, _ []string) error {
+		RunE: func(command *cobra.Command, _ []string) error {
+			devConfig, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			if err := remote.ValidateImageDigest(devConfig.Remote.BinariesImageDigest); err != nil {
+				return err
+			}
+			if err := remote.ValidateImageDigest(binariesImageDigest); err != nil {
+				return err
+			}
+
+			kubeConfigOptions := k8s.GetKubeConfigOptions()
+
 			remoteDevelopment := remote.NewRemoteDevelopment()
 			remoteDevelopment.
-				WithKubernetesClient(k8s.GetKubeConfigFilePath()).
+				WithContext(command.Context()).
+				WithKubeConfigOptions(kubeConfigOptions).
+				WithConfig(devConfig).
 				WithWaitTimeout(int64(waitTimeout)).
-				WithSyncMode(syncModeToMutagenMode[syncMode])
+				WithSyncMode(syncModeToMutagenMode[syncMode]).
+				WithPauseGitOps(pauseGitOps).
+				WithForceConflicts(forceConflicts).
+				WithKeepOnFailure(keepOnFailure).
+				WithPin(pin).
+				WithClone(clone).
+				WithCloneTraffic(cloneTraffic)
+
+			// the flags override the configured images
+			if devImage != "" {
+				remoteDevelopment.WithDevImage(devImage)
+			}
+			if imageRegistry != "" {
+				remoteDevelopment.WithImageRegistry(imageRegistry)
+			}
+			if binariesImage != "" {
+				remoteDevelopment.WithBinariesImage(binariesImage)
+			}
+			if binariesImageDigest != "" {
+				remoteDevelopment.WithBinariesImageDigest(binariesImageDigest)
+			}
+			if len(imagePullSecrets) > 0 {
+				remoteDevelopment.WithImagePullSecrets(imagePullSecrets...)
+			}
 
 			// wizard
-			if namespaceName != "" {
-				remoteDevelopment.WithNamespaceName(namespaceName)
-			} else if err := remoteDevelopment.SelectNamespace(); err != nil {
+			if err := selectNamespace(remoteDevelopment, kubeConfigOptions); err != nil {
 				return err
 			}
 
@@ -76,6 +134,12 @@ func init() {
 				remoteDevelopment.WithStatefulSetName(statefulSetName)
 			} else if daemonSetName != "" {
 				remoteDevelopment.WithDaemonSetName(daemonSetName)
+			} else if jobName != "" {
+				remoteDevelopment.WithJobName(jobName)
+			} else if cronJobName != "" {
+				remoteDevelopment.WithCronJobName(cronJobName)
+			} else if resourceName != "" {
+				remoteDevelopment.WithCustomResourceName(resourceName)
 			} else {
 				if err := remoteDevelopment.SelectResource(); err != nil {
 					return err
@@ -88,6 +152,18 @@ func init() {
 				return err
 			}
 
+			if nodeName != "" {
+				remoteDevelopment.WithNodeName(nodeName)
+			} else if err := remoteDevelopment.SelectNode(); err != nil {
+				return err
+			}
+
+			if ordinal >= 0 {
+				remoteDevelopment.WithOrdinal(ordinal)
+			} else if err := remoteDevelopment.SelectOrdinal(); err != nil {
+				return err
+			}
+
 			if localSyncPath != "" {
 				remoteDevelopment.WithLocalSyncPath(localSyncPath)
 			} else if err := remoteDevelopment.SelectLocalSyncPath(); err != nil {
@@ -106,6 +182,12 @@ func init() {
 				}
 			}
 
+			if dryRun != dryRunNone {
+				return remoteDevelopment.
+					WithDryRun(dryRunToRemoteDryRun[dryRun]).
+					DryRunUp()
+			}
+
 			// bootstrap
 			if err := remoteDevelopment.Up(); err != nil {
 				return err
@@ -122,21 +204,37 @@ func init() {
 		},
 	}
 
-	command.Flags().StringVarP(&namespaceName, "namespace", "n", "", "Kubernetes Namespace")
 	command.Flags().StringVarP(&deploymentName, "deployment", "d", "", "Kubernetes Deployment")
 	command.Flags().StringVarP(&statefulSetName, "statefulset", "s", "", "Kubernetes StatefulSet")
 	command.Flags().StringVarP(&daemonSetName, "daemonset", "t", "", "Kubernetes DaemonSet")
+	command.Flags().StringVar(&jobName, "job", "", "Kubernetes Job, developed on a copy")
+	command.Flags().StringVar(&cronJobName, "cronjob", "", "Kubernetes CronJob, suspended while developing on a Job created from it")
+	command.Flags().StringVar(&resourceName, "resource", "", "Custom workload as <kind>/<name>, e.g. rollout/api")
 	command.Flags().StringVar(&containerName, "container", "", "Kubernetes Container")
+	command.Flags().StringVar(&nodeName, "node", "", "Kubernetes Node of the DaemonSet pod to develop on")
+	command.Flags().IntVar(&ordinal, "ordinal", -1, "Ordinal of the StatefulSet pod to develop on, with --pin; only the highest ordinal can be pinned")
+	command.Flags().BoolVar(&pin, "pin", false, "Only replace the DaemonSet pod of the selected node or the StatefulSet pod of the selected ordinal, leaving the other pods running")
 	command.Flags().StringVarP(&localSyncPath, "local-sync-path", "l", "", "Local folder path to sync")
 	command.Flags().StringVarP(&remoteSyncPath, "remote-sync-path", "r", "", "Remote folder path to sync")
 	command.Flags().StringSliceVarP(&portMappings, "portforward", "p", []string{}, "Port forward: '8080>3000'\nReverse port forward: '9003<9003'\nComma separated: '8080>3000,9003<9003'")
 	command.Flags().IntVarP(&waitTimeout, "wait-timeout", "w", 120, "Time to wait for pod to be ready")
 	command.Flags().BoolVar(&noTTY, "no-tty", false, "Start remote development with no ssh terminal")
+	command.Flags().BoolVar(&clone, "clone", false, "Develop on a copy of the resource, leaving the original untouched")
+	command.Flags().BoolVar(&cloneTraffic, "clone-traffic", false, "Let the Service route traffic to the cloned resource, or the node clone of a pinned DaemonSet, too")
+	command.Flags().BoolVar(&pauseGitOps, "pause-gitops", false, "Pause Argo CD / Flux reconciliation of the resource while the session is active")
+	command.Flags().StringVar(&devImage, "image", "", "Image of the dev container, e.g. with a toolchain; the sync path is still seeded from the workload image")
+	command.Flags().StringVar(&imageRegistry, "image-registry", "", "Registry mirror of the helper images, keeping their repository path")
+	command.Flags().StringVar(&binariesImage, "binaries-image", "", "Full reference of the remote-binaries image")
+	command.Flags().StringVar(&binariesImageDigest, "binaries-image-digest", "", "Digest pinning the remote-binaries image, e.g. sha256:...")
+	command.Flags().StringArrayVar(&imagePullSecrets, "image-pull-secret", []string{}, "Secret added to the imagePullSecrets of the pod, can be repeated")
+	command.Flags().BoolVar(&forceConflicts, "force-conflicts", false, "Take over the fields owned by other field managers without asking")
+	command.Flags().BoolVar(&keepOnFailure, "keep-on-failure", false, "Keep the completed steps when starting the session fails, for troubleshooting")
 	command.Flags().Var(
 		enumflag.New(&syncMode, "sync-mode", syncModeIds, enumflag.EnumCaseSensitive),
 		"sync-mode",
 		"Mutagen sync mode.\nAvailable sync modes: none, two-way-safe, two-way-resolved, one-way-safe, one-way-replica.\n\"none\" sync mode disables mutagen.",
 	)
+	addDryRunFlag(command, &dryRun)
 
 	mainCmd.AddCommand(command)
 }
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"os"
 
 	"bunnyshell.com/dev/cmd/remote"
+	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/util"
 	"github.com/spf13/cobra"
 )
 
@@ -14,11 +17,24 @@ var rootCmd = &cobra.Command{
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// a termination signal cancels the command, which rolls back or closes the session it started
+	ctx, cancel := util.NotifyTermination(context.Background())
+	err := rootCmd.ExecuteContext(ctx)
+	cancel()
+
+	if err != nil {
 		os.Exit(1)
 	}
 }
 
 func init() {
+	kubeConfigOptions := k8s.GetKubeConfigOptions()
+
+	rootCmd.PersistentFlags().StringVar(&kubeConfigOptions.KubeConfigPath, "kubeconfig", "", "Path to the kubeconfig file, instead of the KUBECONFIG files or ~/.kube/config")
+	rootCmd.PersistentFlags().StringVar(&kubeConfigOptions.Context, "context", "", "Kubeconfig context to use, instead of the current context")
+	rootCmd.PersistentFlags().StringVarP(&kubeConfigOptions.Namespace, "namespace", "n", "", "Kubernetes Namespace, instead of the namespace set on the kubeconfig context")
+	rootCmd.PersistentFlags().StringVar(&kubeConfigOptions.As, "as", "", "Username to impersonate")
+	rootCmd.PersistentFlags().StringArrayVar(&kubeConfigOptions.AsGroups, "as-group", []string{}, "Group to impersonate, repeat the flag for multiple groups")
+
 	rootCmd.AddCommand(remote.GetMainCommand())
 }
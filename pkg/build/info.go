@@ -11,7 +11,7 @@ const (
 	LatestReleaseUrl = "https://github.com/bunnyshellosi/dev/releases/latest"
 
 	SSHServerImage   = "public.ecr.aws/x0p9x6p7/bunnyshell/remote-binaries"
-	SSHServerVersion = "0.3.2"
+	SSHServerVersion = "0.4.0"
 
 	MutagenVersion = "v0.15.3"
 )
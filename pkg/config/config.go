@@ -0,0 +1,149 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"bunnyshell.com/dev/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ConfigFilename   = "dev.yaml"
+	ConfigFileEnvVar = "BUNNYSHELL_DEV_CONFIG"
+)
+
+type Config struct {
+	Debug  Debug  `yaml:"debug,omitempty"`
+	Remote Remote `yaml:"remote,omitempty"`
+
+	// custom workload kinds, on top of DefaultWorkloads
+	Workloads []Workload `yaml:"workloads,omitempty"`
+}
+
+type Debug struct {
+	// init containers hidden from the debug container selection, as globs or /regular expressions/
+	RestrictedInitContainers []string `yaml:"restrictedInitContainers,omitempty"`
+}
+
+// Remote selects the images pulled by the remote-dev pod, e.g. from a mirror in air-gapped clusters
+type Remote struct {
+	// image of the dev container, e.g. with a toolchain, instead of the image of the workload
+	Image string `yaml:"image,omitempty"`
+
+	// registry replacing the one of the helper images, e.g. "registry.local/mirror"
+	ImageRegistry string `yaml:"imageRegistry,omitempty"`
+	// full reference of the remote-binaries image, overriding the registry
+	BinariesImage string `yaml:"binariesImage,omitempty"`
+	// digest pinning the remote-binaries image, e.g. "sha256:..."
+	BinariesImageDigest string `yaml:"binariesImageDigest,omitempty"`
+
+	// secrets added to the imagePullSecrets of the patched pod
+	ImagePullSecrets []string `yaml:"imagePullSecrets,omitempty"`
+}
+
+// Workload maps a custom workload kind to the fields needed to patch its pods,
+// paths are dot separated, e.g. "spec.template"
+type Workload struct {
+	Group    string `yaml:"group"`
+	Version  string `yaml:"version,omitempty"`
+	Kind     string `yaml:"kind"`
+	Resource string `yaml:"resource"`
+
+	PodTemplatePath string `yaml:"podTemplatePath"`
+	SelectorPath    string `yaml:"selectorPath,omitempty"`
+	ReplicasPath    string `yaml:"replicasPath,omitempty"`
+
+	// label holding the resource name on its pods, for kinds without a selector
+	SelectorLabel string `yaml:"selectorLabel,omitempty"`
+}
+
+var DefaultWorkloads = []Workload{
+	{
+		Group:           "argoproj.io",
+		Kind:            "Rollout",
+		Resource:        "rollouts",
+		PodTemplatePath: "spec.template",
+		SelectorPath:    "spec.selector",
+		ReplicasPath:    "spec.replicas",
+	},
+	{
+		Group:           "apps.openshift.io",
+		Kind:            "DeploymentConfig",
+		Resource:        "deploymentconfigs",
+		PodTemplatePath: "spec.template",
+		SelectorPath:    "spec.selector",
+		ReplicasPath:    "spec.replicas",
+	},
+	{
+		Group:           "serving.knative.dev",
+		Kind:            "Service",
+		Resource:        "services",
+		PodTemplatePath: "spec.template",
+		SelectorLabel:   "serving.knative.dev/service",
+	},
+}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+// Load reads the configuration file, a missing file results in an empty configuration
+func Load() (*Config, error) {
+	filePath, err := GetConfigFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil && errors.Is(err, os.ErrNotExist) {
+		return NewConfig(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config := NewConfig()
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// GetWorkloads returns the configured workload kinds, overriding the defaults with the same group and kind
+func (c *Config) GetWorkloads() []Workload {
+	workloads := append([]Workload{}, c.Workloads...)
+	for _, defaultWorkload := range DefaultWorkloads {
+		if !hasWorkload(workloads, defaultWorkload.Group, defaultWorkload.Kind) {
+			workloads = append(workloads, defaultWorkload)
+		}
+	}
+
+	return workloads
+}
+
+func hasWorkload(workloads []Workload, group, kind string) bool {
+	for _, workload := range workloads {
+		if workload.Group == group && workload.Kind == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+func GetConfigFilePath() (string, error) {
+	filePath := os.Getenv(ConfigFileEnvVar)
+	if filePath != "" {
+		return filePath, nil
+	}
+
+	workspaceDir, err := util.GetWorkspaceDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(workspaceDir, ConfigFilename), nil
+}
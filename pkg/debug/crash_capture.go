@@ -0,0 +1,159 @@
+package debug
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bunnyshell.com/dev/pkg/k8s/workload"
+	"bunnyshell.com/dev/pkg/util"
+
+	coreV1 "k8s.io/api/core/v1"
+)
+
+const (
+	CrashCaptureFile     = SessionDir + "/crash.log"
+	CrashCaptureTailSize = 100
+
+	previousCrashFilenameFormat = "crash-%s-%s-%d.log"
+)
+
+var ErrCrashCaptureNoEntrypoint = fmt.Errorf("crash capture cannot resolve the image entrypoint, set the container command in the pod spec")
+
+// crashCaptureScript runs the original command, then records its exit code, finish time and
+// last output and keeps the container alive for inspection
+var crashCaptureScript = strings.Join([]string{
+	"mkdir -p %[1]s",
+	"(\"$@\"; echo $? > %[1]s/exit-code) 2>&1 | tee %[1]s/output.log",
+	"{ echo \"exit code: $(cat %[1]s/exit-code)\"; echo \"finished at: $(date -u +%%Y-%%m-%%dT%%H:%%M:%%SZ)\"; echo \"last output:\"; tail -n %[2]d %[1]s/output.log; } > %[3]s",
+	"%[4]s",
+}, "\n")
+
+func (d *DebugComponent) getCrashCaptureCommand(ctx context.Context) ([]string, error) {
+	process, err := d.getContainerProcess(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	command := []string{
+		"sh",
+		"-c",
+		fmt.Sprintf(crashCaptureScript, SessionDir, CrashCaptureTailSize, CrashCaptureFile, d.getKeepAliveScript()),
+		"crash-capture",
+	}
+
+	return append(command, process...), nil
+}
+
+// getContainerProcess returns the original command of the container, falling back to the image
+// entrypoint and, without args, to the image cmd
+func (d *DebugComponent) getContainerProcess(ctx context.Context) ([]string, error) {
+	if len(d.container.Command) > 0 {
+		return append(append([]string{}, d.container.Command...), d.container.Args...), nil
+	}
+
+	imageConfig, err := d.getContainerImageConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCrashCaptureNoEntrypoint, err)
+	}
+
+	args := d.container.Args
+	if len(args) == 0 {
+		args = imageConfig.Cmd
+	}
+
+	process := append(append([]string{}, imageConfig.Entrypoint...), args...)
+	if len(process) == 0 {
+		return nil, ErrCrashCaptureNoEntrypoint
+	}
+
+	return process, nil
+}
+
+// capturePreviousTermination saves the last termination state and logs of the selected container,
+// since patching the resource replaces the crashing pods
+func (d *DebugComponent) capturePreviousTermination(ctx context.Context) error {
+	resource, err := d.getResource()
+	if err != nil {
+		return err
+	}
+
+	resourceSelector, err := d.getResourceSelector()
+	if err != nil {
+		return err
+	}
+
+	pods, err := workload.ListPods(ctx, d.kubernetesClient, resource.GetNamespace(), resourceSelector)
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods {
+		containerStatuses := pod.Status.ContainerStatuses
+		if d.isInitContainer {
+			containerStatuses = pod.Status.InitContainerStatuses
+		}
+
+		for _, containerStatus := range containerStatuses {
+			if containerStatus.Name != d.container.Name || containerStatus.LastTerminationState.Terminated == nil {
+				continue
+			}
+
+			return d.savePreviousTermination(ctx, &pod, containerStatus.LastTerminationState.Terminated)
+		}
+	}
+
+	return nil
+}
+
+func (d *DebugComponent) savePreviousTermination(ctx context.Context, pod *coreV1.Pod, terminated *coreV1.ContainerStateTerminated) error {
+	tailLines := int64(CrashCaptureTailSize)
+	logs, err := d.kubernetesClient.GetPodLogs(ctx, pod.GetNamespace(), pod.GetName(), &coreV1.PodLogOptions{
+		Container: d.container.Name,
+		Previous:  true,
+		TailLines: &tailLines,
+	})
+	if err != nil {
+		return err
+	}
+
+	report := fmt.Sprintf(
+		"pod: %s\ncontainer: %s\nexit code: %d\nreason: %s\nfinished at: %s\nlast output:\n%s",
+		pod.GetName(),
+		d.container.Name,
+		terminated.ExitCode,
+		terminated.Reason,
+		terminated.FinishedAt.UTC().Format(time.RFC3339),
+		logs,
+	)
+
+	workspace, err := util.GetRemoteDevWorkspaceDir()
+	if err != nil {
+		return err
+	}
+
+	resource, err := d.getResource()
+	if err != nil {
+		return err
+	}
+
+	reportPath := filepath.Join(workspace, fmt.Sprintf(previousCrashFilenameFormat, resource.GetName(), d.container.Name, d.startedAt))
+	if err := os.WriteFile(reportPath, []byte(report), 0600); err != nil {
+		return err
+	}
+
+	d.StopSpinner()
+	fmt.Printf(
+		"Previous run of %s exited with code %d (%s), logs saved to %s\n",
+		d.container.Name,
+		terminated.ExitCode,
+		terminated.Reason,
+		reportPath,
+	)
+	d.StartSpinner("")
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package debug
+
+import (
+	"bunnyshell.com/dev/pkg/k8s/workload"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var ErrWorkloadNotConfigured = workload.ErrWorkloadNotConfigured
+
+func (d *DebugComponent) WithCustomResource(resource *unstructured.Unstructured) *DebugComponent {
+	return d.WithResource(resource)
+}
+
+// WithCustomResourceName selects a custom workload given as "<kind>/<name>", e.g. "rollout/api"
+func (d *DebugComponent) WithCustomResourceName(resourceName string) *DebugComponent {
+	resource, err := workload.GetCustomResource(d.getContext(), d.kubernetesClient, d.customKinds, d.namespace.GetName(), resourceName)
+	if err != nil {
+		panic(err)
+	}
+
+	return d.WithWorkload(resource)
+}
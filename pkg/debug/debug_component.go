@@ -1,11 +1,13 @@
 package debug
 
 import (
+	"context"
 	"fmt"
 	"time"
-	"strings"
 
+	"bunnyshell.com/dev/pkg/config"
 	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/k8s/workload"
 	"bunnyshell.com/dev/pkg/remote/container"
 	"bunnyshell.com/dev/pkg/util"
 
@@ -14,13 +16,15 @@ import (
 	coreV1 "k8s.io/api/core/v1"
 )
 
-// +enum
-type ResourceType string
+type ResourceType = workload.ResourceType
 
 const (
-	Deployment  ResourceType = "deployment"
-	StatefulSet ResourceType = "statefulset"
-	DaemonSet   ResourceType = "daemonset"
+	Deployment  = workload.Deployment
+	StatefulSet = workload.StatefulSet
+	DaemonSet   = workload.DaemonSet
+
+	// CustomResource is any workload kind mapped in the configuration
+	CustomResource = workload.CustomResource
 )
 
 type DebugComponent struct {
@@ -35,15 +39,27 @@ type DebugComponent struct {
 
 	namespace    *coreV1.Namespace
 	resourceType ResourceType
-	deployment   *appsV1.Deployment
-	statefulSet  *appsV1.StatefulSet
-	daemonSet    *appsV1.DaemonSet
+	workload     workload.Workload
 	container    *coreV1.Container
 
+	// workload kinds mapped in the configuration
+	customKinds []config.Workload
+
 	isInitContainer bool
 
+	restrictedInitContainers []*containerNameRule
+
+	crashCapture bool
+
+	ephemeral              bool
+	toolboxImage           string
+	imageRegistry          string
+	ephemeralPod           *coreV1.Pod
+	ephemeralContainerName string
+
 	shouldPrepareResource bool
 
+	ctx         context.Context
 	stopChannel chan bool
 
 	startedAt   int64
@@ -51,6 +67,11 @@ type DebugComponent struct {
 }
 
 func NewDebugComponent() *DebugComponent {
+	restrictedInitContainers, err := newContainerNameRules(DefaultRestrictedInitContainers)
+	if err != nil {
+		panic(err)
+	}
+
 	return &DebugComponent{
 		ContainerConfig: *container.NewConfig(),
 
@@ -58,15 +79,26 @@ func NewDebugComponent() *DebugComponent {
 
 		shouldPrepareResource: true,
 
+		customKinds: config.DefaultWorkloads,
+
 		stopChannel: make(chan bool),
 		spinner:     util.MakeSpinner(" Debug"),
 		startedAt:   time.Now().Unix(),
 		waitTimeout: 120,
+
+		restrictedInitContainers: restrictedInitContainers,
 	}
 }
 
+// WithKubernetesClient loads the kubeconfig file alone
+//
+// Deprecated: use WithKubeConfigOptions
 func (d *DebugComponent) WithKubernetesClient(kubeConfigPath string) *DebugComponent {
-	kubernetesClient, err := k8s.NewKubernetesClient(kubeConfigPath)
+	return d.WithKubeConfigOptions(&k8s.KubeConfigOptions{KubeConfigPath: kubeConfigPath})
+}
+
+func (d *DebugComponent) WithKubeConfigOptions(kubeConfigOptions *k8s.KubeConfigOptions) *DebugComponent {
+	kubernetesClient, err := k8s.NewKubernetesClientFromOptions(kubeConfigOptions)
 	if err != nil {
 		panic(err)
 	}
@@ -76,13 +108,27 @@ func (d *DebugComponent) WithKubernetesClient(kubeConfigPath string) *DebugCompo
 	return d
 }
 
+// WithContext cancels the session setup and ends the session when the context is done
+func (d *DebugComponent) WithContext(ctx context.Context) *DebugComponent {
+	d.ctx = ctx
+	return d
+}
+
+func (d *DebugComponent) getContext() context.Context {
+	if d.ctx == nil {
+		return context.Background()
+	}
+
+	return d.ctx
+}
+
 func (d *DebugComponent) WithNamespace(namespace *coreV1.Namespace) *DebugComponent {
 	d.namespace = namespace
 	return d
 }
 
 func (d *DebugComponent) WithNamespaceName(namespaceName string) *DebugComponent {
-	namespace, err := d.kubernetesClient.GetNamespace(namespaceName)
+	namespace, err := d.kubernetesClient.GetNamespace(d.getContext(), namespaceName)
 	if err != nil {
 		panic(err)
 	}
@@ -104,85 +150,56 @@ func (d *DebugComponent) WithResourceType(resourceType ResourceType) *DebugCompo
 	return d
 }
 
-func (d *DebugComponent) WithDeployment(deployment *appsV1.Deployment) *DebugComponent {
+func (d *DebugComponent) WithWorkload(resource workload.Workload) *DebugComponent {
 	if d.namespace == nil {
 		panic(ErrNoNamespaceSelected)
 	}
 
-	if d.namespace.GetName() != deployment.GetNamespace() {
+	if d.namespace.GetName() != resource.GetNamespace() {
 		panic(fmt.Errorf(
-			"the deployment's namespace(\"%s\") doesn't match the selected namespace \"%s\"",
-			deployment.GetNamespace(),
+			"the %s's namespace(\"%s\") doesn't match the selected namespace \"%s\"",
+			workload.GetLabel(resource),
+			resource.GetNamespace(),
 			d.namespace.GetName(),
 		))
 	}
 
-	d.WithResourceType(Deployment)
-	d.deployment = deployment
+	d.WithResourceType(resource.GetResourceType())
+	d.workload = resource
 	return d
 }
 
-func (d *DebugComponent) WithDeploymentName(deploymentName string) *DebugComponent {
-	deployment, err := d.kubernetesClient.GetDeployment(d.namespace.GetName(), deploymentName)
+func (d *DebugComponent) withResourceName(resourceType ResourceType, name string) *DebugComponent {
+	resource, err := workload.Get(d.getContext(), d.kubernetesClient, resourceType, d.namespace.GetName(), name)
 	if err != nil {
 		panic(err)
 	}
 
-	return d.WithDeployment(deployment)
+	return d.WithWorkload(resource)
 }
 
-func (d *DebugComponent) WithStatefulSet(statefulSet *appsV1.StatefulSet) *DebugComponent {
-	if d.namespace == nil {
-		panic(ErrNoNamespaceSelected)
-	}
+func (d *DebugComponent) WithDeployment(deployment *appsV1.Deployment) *DebugComponent {
+	return d.WithWorkload(workload.NewDeployment(d.kubernetesClient, deployment))
+}
 
-	if d.namespace.GetName() != statefulSet.GetNamespace() {
-		panic(fmt.Errorf(
-			"the statefulset's namespace(\"%s\") doesn't match the selected namespace \"%s\"",
-			statefulSet.GetNamespace(),
-			d.namespace.GetName(),
-		))
-	}
+func (d *DebugComponent) WithDeploymentName(deploymentName string) *DebugComponent {
+	return d.withResourceName(Deployment, deploymentName)
+}
 
-	d.WithResourceType(StatefulSet)
-	d.statefulSet = statefulSet
-	return d
+func (d *DebugComponent) WithStatefulSet(statefulSet *appsV1.StatefulSet) *DebugComponent {
+	return d.WithWorkload(workload.NewStatefulSet(d.kubernetesClient, statefulSet))
 }
 
 func (d *DebugComponent) WithStatefulSetName(name string) *DebugComponent {
-	statefulSet, err := d.kubernetesClient.GetStatefulSet(d.namespace.GetName(), name)
-	if err != nil {
-		panic(err)
-	}
-
-	return d.WithStatefulSet(statefulSet)
+	return d.withResourceName(StatefulSet, name)
 }
 
 func (d *DebugComponent) WithDaemonSet(daemonSet *appsV1.DaemonSet) *DebugComponent {
-	if d.namespace == nil {
-		panic(ErrNoNamespaceSelected)
-	}
-
-	if d.namespace.GetName() != daemonSet.GetNamespace() {
-		panic(fmt.Errorf(
-			"the daemonset's namespace(\"%s\") doesn't match the selected namespace \"%s\"",
-			daemonSet.GetNamespace(),
-			d.namespace.GetName(),
-		))
-	}
-
-	d.WithResourceType(DaemonSet)
-	d.daemonSet = daemonSet
-	return d
+	return d.WithWorkload(workload.NewDaemonSet(d.kubernetesClient, daemonSet))
 }
 
 func (d *DebugComponent) WithDaemonSetName(name string) *DebugComponent {
-	daemonSet, err := d.kubernetesClient.GetDaemonSet(d.namespace.GetName(), name)
-	if err != nil {
-		panic(err)
-	}
-
-	return d.WithDaemonSet(daemonSet)
+	return d.withResourceName(DaemonSet, name)
 }
 
 func (d *DebugComponent) WithContainer(container *coreV1.Container) *DebugComponent {
@@ -206,74 +223,87 @@ func (d *DebugComponent) WithInitContainer(container *coreV1.Container) *DebugCo
 }
 
 func (d *DebugComponent) WithContainerName(containerName string) *DebugComponent {
-	container, err := d.getResourceContainer(containerName)
+	containers, err := d.getResourceContainers()
 	if err != nil {
-	    if !strings.HasSuffix(err.Error(), " not found") {
-	        panic(err)
-	    }
-
-		initContainer, err := d.getResourceInitContainer(containerName)
-		if err != nil {
-		    panic(err)
-		}
+		panic(err)
+	}
 
-        return d.WithInitContainer(initContainer)
+	initContainers, err := d.getResourceInitContainers()
+	if err != nil {
+		panic(err)
 	}
 
-	return d.WithContainer(container)
-}
+	container, isInit, err := workload.FindContainer(containers, initContainers, containerName)
+	if err != nil {
+		panic(err)
+	}
 
-func (d *DebugComponent) getResource() (Resource, error) {
-	switch d.resourceType {
-	case Deployment:
-		return d.deployment, nil
-	case StatefulSet:
-		return d.statefulSet, nil
-	case DaemonSet:
-		return d.daemonSet, nil
-	default:
-		return nil, d.resourceTypeNotSupportedError()
+	if isInit {
+		return d.WithInitContainer(container.DeepCopy())
 	}
+
+	return d.WithContainer(container.DeepCopy())
 }
 
-func (d *DebugComponent) getResourceType(resource Resource) (ResourceType, error) {
-	switch resource.(type) {
-	case *appsV1.Deployment:
-		return Deployment, nil
-	case *appsV1.StatefulSet:
-		return StatefulSet, nil
-	case *appsV1.DaemonSet:
-		return DaemonSet, nil
-	default:
-		return "", ErrInvalidResourceType
+func (d *DebugComponent) getResource() (workload.Workload, error) {
+	if d.workload == nil {
+		return nil, ErrNoResourceSelected
 	}
+
+	return d.workload, nil
 }
 
 func (d *DebugComponent) WithResource(resource Resource) *DebugComponent {
-	resourceType, err := d.getResourceType(resource)
+	resourceWorkload, err := workload.New(d.kubernetesClient, resource, d.customKinds)
 	if err != nil {
 		panic(err)
 	}
 
-	switch resourceType {
-	case Deployment:
-		d.WithDeployment(resource.(*appsV1.Deployment))
-	case StatefulSet:
-		d.WithStatefulSet(resource.(*appsV1.StatefulSet))
-	case DaemonSet:
-		d.WithDaemonSet(resource.(*appsV1.DaemonSet))
-	default:
-		panic(fmt.Errorf(
-			"could not determine the resource Kind for resource type \"%s\"",
-			resourceType,
-		))
+	return d.WithWorkload(resourceWorkload)
+}
+
+func (d *DebugComponent) WithWaitTimeout(waitTimeout int64) *DebugComponent {
+	d.waitTimeout = waitTimeout
+	return d
+}
+
+// WithRestrictedInitContainers hides more init containers from selection, on top of the defaults
+func (d *DebugComponent) WithRestrictedInitContainers(rules ...string) *DebugComponent {
+	restrictedInitContainers, err := newContainerNameRules(rules)
+	if err != nil {
+		panic(err)
 	}
 
+	d.restrictedInitContainers = append(d.restrictedInitContainers, restrictedInitContainers...)
 	return d
 }
 
-func (d *DebugComponent) WithWaitTimeout(waitTimeout int64) *DebugComponent {
-	d.waitTimeout = waitTimeout
+func (d *DebugComponent) WithConfig(config *config.Config) *DebugComponent {
+	d.customKinds = config.GetWorkloads()
+
+	return d.
+		WithImageRegistry(config.Remote.ImageRegistry).
+		WithRestrictedInitContainers(config.Debug.RestrictedInitContainers...)
+}
+
+// WithImageRegistry pulls the default toolbox image from a mirror, keeping its repository path
+func (d *DebugComponent) WithImageRegistry(imageRegistry string) *DebugComponent {
+	d.imageRegistry = imageRegistry
+	return d
+}
+
+func (d *DebugComponent) WithCrashCapture(crashCapture bool) *DebugComponent {
+	d.crashCapture = crashCapture
+	return d
+}
+
+func (d *DebugComponent) WithEphemeral(ephemeral bool) *DebugComponent {
+	d.ephemeral = ephemeral
+	return d
+}
+
+func (d *DebugComponent) WithToolboxImage(toolboxImage string) *DebugComponent {
+	d.toolboxImage = toolboxImage
 	return d
 }
 
@@ -0,0 +1,144 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	k8sTools "bunnyshell.com/dev/pkg/k8s/tools"
+	"bunnyshell.com/dev/pkg/util"
+
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	DefaultToolboxImage = "busybox:1.36"
+
+	ephemeralContainerNameFormat = "debugger-%d"
+)
+
+var (
+	ErrEphemeralInitContainer = fmt.Errorf("ephemeral debug containers cannot target init containers")
+	ErrNoEphemeralContainer   = fmt.Errorf("no ephemeral debug container started")
+)
+
+// startEphemeralContainer adds a toolbox container sharing the process namespace of the selected
+// container to a running pod, leaving the workload and the pod untouched otherwise
+func (d *DebugComponent) startEphemeralContainer(ctx context.Context) error {
+	if d.isInitContainer {
+		return ErrEphemeralInitContainer
+	}
+
+	d.StartSpinner(" Start ephemeral debug container")
+	defer d.StopSpinner()
+
+	pod, err := d.getDebugPod(ctx)
+	if err != nil {
+		return err
+	}
+
+	securityContext, err := d.getEphemeralSecurityContext(pod)
+	if err != nil {
+		return err
+	}
+
+	containerName := fmt.Sprintf(ephemeralContainerNameFormat, d.startedAt)
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, coreV1.EphemeralContainer{
+		EphemeralContainerCommon: coreV1.EphemeralContainerCommon{
+			Name:            containerName,
+			Image:           d.getToolboxImage(),
+			ImagePullPolicy: coreV1.PullIfNotPresent,
+			Command:         []string{"sh"},
+			Stdin:           true,
+			TTY:             true,
+			SecurityContext: securityContext,
+		},
+		TargetContainerName: d.container.Name,
+	})
+
+	pod, err = d.kubernetesClient.UpdatePodEphemeralContainers(ctx, pod.GetNamespace(), pod)
+	if err != nil {
+		return err
+	}
+
+	d.ephemeralPod = pod
+	d.ephemeralContainerName = containerName
+
+	return d.waitEphemeralContainerRunning(ctx)
+}
+
+// getToolboxImage returns the full toolbox image reference, or the default one pulled from the mirror
+func (d *DebugComponent) getToolboxImage() string {
+	if d.toolboxImage != "" {
+		return d.toolboxImage
+	}
+
+	return k8sTools.GetMirrorImage(DefaultToolboxImage, d.imageRegistry)
+}
+
+// getEphemeralSecurityContext runs the toolbox as the target container user, as required by restricted namespaces
+func (d *DebugComponent) getEphemeralSecurityContext(pod *coreV1.Pod) (*coreV1.SecurityContext, error) {
+	runAsUser, runAsGroup := k8sTools.GetRunAsIdentity(&pod.Spec, d.container)
+	applyConfiguration := k8sTools.GetInjectedSecurityContext(runAsUser, runAsGroup, k8sTools.IsRestrictedNamespace(d.namespace))
+
+	data, err := json.Marshal(applyConfiguration)
+	if err != nil {
+		return nil, err
+	}
+
+	securityContext := &coreV1.SecurityContext{}
+	if err := json.Unmarshal(data, securityContext); err != nil {
+		return nil, err
+	}
+
+	return securityContext, nil
+}
+
+func (d *DebugComponent) waitEphemeralContainerRunning(ctx context.Context) error {
+	startTimestamp := time.Now().Unix()
+	for {
+		pod, err := d.kubernetesClient.GetPod(ctx, d.ephemeralPod.GetNamespace(), d.ephemeralPod.GetName())
+		if err != nil {
+			return err
+		}
+
+		for _, containerStatus := range pod.Status.EphemeralContainerStatuses {
+			if containerStatus.Name != d.ephemeralContainerName {
+				continue
+			}
+
+			if containerStatus.State.Running != nil {
+				d.ephemeralPod = pod
+				return nil
+			}
+
+			if containerStatus.State.Terminated != nil {
+				return fmt.Errorf("ephemeral debug container terminated: %s", containerStatus.State.Terminated.Reason)
+			}
+		}
+
+		nowTimestamp := time.Now().Unix()
+		if nowTimestamp-startTimestamp >= d.waitTimeout {
+			break
+		}
+
+		if err := util.Sleep(ctx, 1*time.Second); err != nil {
+			return err
+		}
+	}
+
+	// timeout reached
+	return fmt.Errorf("ephemeral debug container not running")
+}
+
+func (d *DebugComponent) attachEphemeralContainer(ctx context.Context) error {
+	if d.ephemeralPod == nil {
+		return ErrNoEphemeralContainer
+	}
+
+	return d.streamTerminal(func(streamOptions remotecommand.StreamOptions) error {
+		return d.kubernetesClient.Attach(ctx, d.ephemeralPod, d.ephemeralContainerName, streamOptions)
+	})
+}
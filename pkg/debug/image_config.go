@@ -0,0 +1,304 @@
+package debug
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	k8sTools "bunnyshell.com/dev/pkg/k8s/tools"
+
+	coreV1 "k8s.io/api/core/v1"
+)
+
+const dockerHubRegistryHost = "registry-1.docker.io"
+
+var (
+	ErrImageRegistryUnauthorized = fmt.Errorf("image registry denied the access")
+
+	manifestMediaTypes = []string{
+		"application/vnd.oci.image.index.v1+json",
+		"application/vnd.oci.image.manifest.v1+json",
+		"application/vnd.docker.distribution.manifest.list.v2+json",
+		"application/vnd.docker.distribution.manifest.v2+json",
+	}
+
+	challengeParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+// imageConfig is the process the image starts when the container does not override it
+type imageConfig struct {
+	Entrypoint []string `json:"Entrypoint"`
+	Cmd        []string `json:"Cmd"`
+}
+
+type imageManifest struct {
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+
+	Manifests []struct {
+		Digest   string `json:"digest"`
+		Platform struct {
+			OS string `json:"os"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+type dockerConfig struct {
+	Auths map[string]dockerConfigAuth `json:"auths"`
+}
+
+type dockerConfigAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
+
+// registryClient reads the image config through the registry HTTP API, with the pull secrets of the pod
+type registryClient struct {
+	httpClient *http.Client
+
+	scheme     string
+	host       string
+	repository string
+
+	username string
+	password string
+	token    string
+}
+
+func newRegistryClient(image string) (*registryClient, string) {
+	domain, path := k8sTools.SplitImageDomain(image)
+
+	reference := "latest"
+	if repository, digest, ok := strings.Cut(path, "@"); ok {
+		path, reference = repository, digest
+	} else if index := strings.LastIndex(path, ":"); index != -1 {
+		path, reference = path[:index], path[index+1:]
+	}
+
+	host := domain
+	if domain == k8sTools.DockerHubDomain {
+		host = dockerHubRegistryHost
+	}
+
+	return &registryClient{
+		httpClient: http.DefaultClient,
+		scheme:     "https",
+		host:       host,
+		repository: path,
+	}, reference
+}
+
+func (c *registryClient) withDockerConfig(data []byte) error {
+	config := dockerConfig{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return err
+	}
+
+	for server, auth := range config.Auths {
+		if getRegistryHost(server) != c.host {
+			continue
+		}
+
+		c.username, c.password = auth.Username, auth.Password
+		if auth.Auth == "" {
+			return nil
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return err
+		}
+
+		c.username, c.password, _ = strings.Cut(string(decoded), ":")
+
+		return nil
+	}
+
+	return nil
+}
+
+func (c *registryClient) getImageConfig(ctx context.Context, reference string) (*imageConfig, error) {
+	manifest := imageManifest{}
+	if err := c.getJSON(ctx, "manifests/"+reference, strings.Join(manifestMediaTypes, ","), &manifest); err != nil {
+		return nil, err
+	}
+
+	// the entrypoint is the same for every platform of an index, the attestations are skipped
+	for _, platformManifest := range manifest.Manifests {
+		if platformManifest.Platform.OS != "linux" {
+			continue
+		}
+
+		return c.getImageConfig(ctx, platformManifest.Digest)
+	}
+
+	if manifest.Config.Digest == "" {
+		return nil, fmt.Errorf("no image config in manifest %s", reference)
+	}
+
+	blob := struct {
+		Config imageConfig `json:"config"`
+	}{}
+	if err := c.getJSON(ctx, "blobs/"+manifest.Config.Digest, "", &blob); err != nil {
+		return nil, err
+	}
+
+	return &blob.Config, nil
+}
+
+func (c *registryClient) getJSON(ctx context.Context, path, accept string, target any) error {
+	response, err := c.get(ctx, path, accept)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusUnauthorized && c.token == "" {
+		if err := c.authorize(ctx, response.Header.Get("WWW-Authenticate")); err != nil {
+			return err
+		}
+
+		return c.getJSON(ctx, path, accept, target)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot get %s from %s: %s", path, c.host, response.Status)
+	}
+
+	return json.NewDecoder(response.Body).Decode(target)
+}
+
+func (c *registryClient) get(ctx context.Context, path, accept string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s://%s/v2/%s/%s", c.scheme, c.host, c.repository, path), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+
+	if c.token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.username != "" {
+		request.SetBasicAuth(c.username, c.password)
+	}
+
+	return c.httpClient.Do(request)
+}
+
+// authorize gets an anonymous or pull secret token, as asked by the Bearer challenge of the registry
+func (c *registryClient) authorize(ctx context.Context, challenge string) error {
+	scheme, challengeParams, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return ErrImageRegistryUnauthorized
+	}
+
+	params := map[string]string{}
+	for _, match := range challengeParamRegexp.FindAllStringSubmatch(challengeParams, -1) {
+		params[match[1]] = match[2]
+	}
+
+	query := url.Values{}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", c.repository))
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, params["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	if c.username != "" {
+		request.SetBasicAuth(c.username, c.password)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s", ErrImageRegistryUnauthorized, response.Status)
+	}
+
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return err
+	}
+
+	c.token = token.Token
+	if c.token == "" {
+		c.token = token.AccessToken
+	}
+
+	if c.token == "" {
+		return ErrImageRegistryUnauthorized
+	}
+
+	return nil
+}
+
+// getRegistryHost matches the server keys of a docker config, e.g. https://index.docker.io/v1/
+func getRegistryHost(server string) string {
+	if _, host, ok := strings.Cut(server, "://"); ok {
+		server = host
+	}
+
+	host, _, _ := strings.Cut(server, "/")
+	switch host {
+	case k8sTools.DockerHubDomain, "index.docker.io":
+		return dockerHubRegistryHost
+	}
+
+	return host
+}
+
+// getContainerImageConfig reads the image config of the selected container from the registry
+func (d *DebugComponent) getContainerImageConfig(ctx context.Context) (*imageConfig, error) {
+	client, reference := newRegistryClient(d.container.Image)
+
+	resource, err := d.getResource()
+	if err != nil {
+		return nil, err
+	}
+
+	podSpec, err := resource.GetPodSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, imagePullSecret := range podSpec.ImagePullSecrets {
+		secret, err := d.kubernetesClient.GetSecret(ctx, resource.GetNamespace(), imagePullSecret.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		data, ok := secret.Data[coreV1.DockerConfigJsonKey]
+		if !ok {
+			continue
+		}
+
+		if err := client.withDockerConfig(data); err != nil {
+			return nil, fmt.Errorf("invalid image pull secret %s: %w", imagePullSecret.Name, err)
+		}
+
+		if client.username != "" {
+			break
+		}
+	}
+
+	return client.getImageConfig(ctx, reference)
+}
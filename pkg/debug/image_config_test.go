@@ -0,0 +1,64 @@
+package debug
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetImageConfig(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if user, password, _ := r.BasicAuth(); user != "ci" || password != "secret" || r.URL.Query().Get("scope") != "repository:team/api:pull" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"token":"pull"}`))
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer pull" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		responses := map[string]string{
+			"/v2/team/api/manifests/1.0":          `{"manifests":[{"digest":"sha256:attestation","platform":{"os":"unknown"}},{"digest":"sha256:linux","platform":{"os":"linux"}}]}`,
+			"/v2/team/api/manifests/sha256:linux": `{"config":{"digest":"sha256:config"}}`,
+			"/v2/team/api/blobs/sha256:config":    `{"config":{"Entrypoint":["/entrypoint.sh"],"Cmd":["serve"]}}`,
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	client, reference := newRegistryClient(host + "/team/api:1.0")
+	client.httpClient = server.Client()
+
+	// base64 of ci:secret
+	if err := client.withDockerConfig([]byte(`{"auths":{"https://` + host + `/v1/":{"auth":"Y2k6c2VjcmV0"}}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := client.getImageConfig(context.Background(), reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &imageConfig{Entrypoint: []string{"/entrypoint.sh"}, Cmd: []string{"serve"}}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+}
@@ -1,21 +1,17 @@
 package debug
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
-	"time"
 
-	"bunnyshell.com/dev/pkg/k8s/patch"
+	"bunnyshell.com/dev/pkg/k8s/workload"
 
-	k8sTools "bunnyshell.com/dev/pkg/k8s/tools"
-	appsV1 "k8s.io/api/apps/v1"
 	coreV1 "k8s.io/api/core/v1"
 	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
-	appsCoreV1 "k8s.io/client-go/applyconfigurations/apps/v1"
 	applyCoreV1 "k8s.io/client-go/applyconfigurations/core/v1"
-	applyMetaV1 "k8s.io/client-go/applyconfigurations/meta/v1"
 )
 
 const (
@@ -26,75 +22,37 @@ const (
 	MetadataContainer = MetadataPrefix + "container"
 	MetadataRollback  = MetadataPrefix + "rollback-manifest"
 
-	MetadataKubeCTLLastAppliedConf = "kubectl.kubernetes.io/last-applied-configuration"
-	MetadataK8SRevision            = "deployment.kubernetes.io/revision"
+	MetadataKubeCTLLastAppliedConf = workload.MetadataKubeCTLLastAppliedConf
+	MetadataK8SRevision            = workload.MetadataK8SRevision
 
 	RemoteDevMetadataActive = "remote-dev.bunnyshell.com/active"
-)
 
-var (
-	ErrInvalidResourceType = fmt.Errorf("invalid resource type")
+	VolumeNameSession = "debug-session"
+	SessionDir        = "/tmp/bunnyshell-debug"
+
+	// InitContainerDoneFile lets a debugged init container exit, so the rest of the init chain runs
+	InitContainerDoneFile = SessionDir + "/done"
 )
 
-type Resource interface {
-	GetName() string
-	GetNamespace() string
-	GetAnnotations() map[string]string
-	GetLabels() map[string]string
-}
+var (
+	ErrInvalidResourceType = workload.ErrInvalidResourceType
 
-func (d *DebugComponent) resourceTypeNotSupportedError() error {
-	return fmt.Errorf("resource type \"%s\" not supported", d.resourceType)
-}
+	// resetContainerFields are dropped from the debugged container, which runs the keep-alive script instead
+	resetContainerFields = []string{"args", "readinessProbe", "livenessProbe", "startupProbe"}
+)
 
-func (d *DebugComponent) getResourcePatch() (patch.Resource, error) {
-	switch d.resourceType {
-	case Deployment:
-		var replicas int32 = 1
-		strategy := appsV1.RecreateDeploymentStrategyType
-		return &patch.DeploymentPatchConfiguration{
-			ObjectMetaApplyConfiguration: &applyMetaV1.ObjectMetaApplyConfiguration{},
-			Spec: &patch.DeploymentSpecPatchConfiguration{
-				Strategy: &patch.DeploymentStrategyPatchConfiguration{
-					Type:          &strategy,
-					RollingUpdate: nil,
-				},
-				Replicas: &replicas,
-			},
-		}, nil
-	case StatefulSet:
-		var replicas int32 = 1
-		strategy := appsV1.OnDeleteStatefulSetStrategyType
-		return &patch.StatefulSetPatchConfiguration{
-			ObjectMetaApplyConfiguration: &applyMetaV1.ObjectMetaApplyConfiguration{},
-			Spec: &patch.StatefulSetSpecPatchConfiguration{
-				UpdateStrategy: &patch.StatefulSetStrategyPatchConfiguration{
-					Type:          &strategy,
-					RollingUpdate: nil,
-				},
-				Replicas: &replicas,
-			},
-		}, nil
-	case DaemonSet:
-		strategy := appsV1.OnDeleteDaemonSetStrategyType
-		return &patch.DaemonSetPatchConfiguration{
-			ObjectMetaApplyConfiguration: &applyMetaV1.ObjectMetaApplyConfiguration{},
-			Spec: &patch.DaemonSetSpecPatchConfiguration{
-				UpdateStrategy: &patch.DaemonSetStrategyPatchConfiguration{
-					Type:          &strategy,
-					RollingUpdate: nil,
-				},
-			},
-		}, nil
-	default:
-		return nil, d.resourceTypeNotSupportedError()
-	}
-}
+type Resource = workload.Resource
 
-func (d *DebugComponent) prepareResource() error {
+func (d *DebugComponent) prepareResource(ctx context.Context) error {
 	d.StartSpinner(" Setup k8s pod for debugging")
 	defer d.StopSpinner()
 
+	if d.crashCapture {
+		if err := d.capturePreviousTermination(ctx); err != nil {
+			return err
+		}
+	}
+
 	currentManifestSnapshot, err := d.getCurrentManifestSnapshot()
 	if err != nil {
 		return err
@@ -105,7 +63,7 @@ func (d *DebugComponent) prepareResource() error {
 		return err
 	}
 
-	resourcePatch, err := d.getResourcePatch()
+	resourcePatch, err := resource.GetPatch()
 	if err != nil {
 		return err
 	}
@@ -123,7 +81,7 @@ func (d *DebugComponent) prepareResource() error {
 	resourcePatch.WithAnnotations(annotations).WithLabels(labels)
 
 	podTemplateSpec := applyCoreV1.PodTemplateSpec()
-	if err := d.preparePodTemplateSpec(podTemplateSpec); err != nil {
+	if err := d.preparePodTemplateSpec(ctx, podTemplateSpec); err != nil {
 		return err
 	}
 	resourcePatch.WithSpecTemplate(podTemplateSpec)
@@ -133,74 +91,14 @@ func (d *DebugComponent) prepareResource() error {
 		return err
 	}
 
-	if err := d.resetResourceContainer(resource); err != nil {
+	if err := resource.TakeOver(ctx, d.container.Name, resetContainerFields, data); err != nil {
 		return fmt.Errorf("cannot reset container: %w", err)
 	}
 
-	switch d.resourceType {
-	case Deployment:
-		return d.kubernetesClient.PatchDeployment(resource.GetNamespace(), resource.GetName(), data)
-	case StatefulSet:
-		return d.kubernetesClient.PatchStatefulSet(resource.GetNamespace(), resource.GetName(), data)
-	case DaemonSet:
-		return d.kubernetesClient.PatchDaemonSet(resource.GetNamespace(), resource.GetName(), data)
-	default:
-		return d.resourceTypeNotSupportedError()
-	}
+	return resource.Apply(ctx, data, workload.ApplyOptions{Force: true})
 }
 
-func (d *DebugComponent) resetResourceContainer(resource Resource) error {
-	containerIndex, isInit, err := d.getContainerIndex()
-	if err != nil {
-		return err
-	}
-
-    specPath := "containers"
-    if isInit {
-        specPath = "initContainers"
-    }
-
-	// we need to use replace because remove fails if the path is missing
-	resetJSON, err := json.Marshal([]map[string]any{
-		{
-			"op":    "replace",
-			"path":  fmt.Sprintf("/spec/template/spec/%s/%d/args", specPath, containerIndex),
-			"value": []string{},
-		},
-		{
-			"op":    "replace",
-			"path":  fmt.Sprintf("/spec/template/spec/%s/%d/readinessProbe", specPath, containerIndex),
-			"value": nil,
-		},
-		{
-			"op":    "replace",
-			"path":  fmt.Sprintf("/spec/template/spec/%s/%d/livenessProbe", specPath, containerIndex),
-			"value": nil,
-		},
-		{
-			"op":    "replace",
-			"path":  fmt.Sprintf("/spec/template/spec/%s/%d/startupProbe", specPath, containerIndex),
-			"value": nil,
-		},
-	})
-
-	if err != nil {
-		return err
-	}
-
-	switch d.resourceType {
-	case Deployment:
-		return d.kubernetesClient.BatchPatchDeployment(resource.GetNamespace(), resource.GetName(), resetJSON)
-	case StatefulSet:
-		return d.kubernetesClient.BatchPatchStatefulSet(resource.GetNamespace(), resource.GetName(), resetJSON)
-	case DaemonSet:
-		return d.kubernetesClient.BatchPatchDaemonSet(resource.GetNamespace(), resource.GetName(), resetJSON)
-	default:
-		return d.resourceTypeNotSupportedError()
-	}
-}
-
-func (d *DebugComponent) restoreDeployment() error {
+func (d *DebugComponent) restoreDeployment(ctx context.Context) error {
 	resource, err := d.getResource()
 	if err != nil {
 		return err
@@ -211,136 +109,19 @@ func (d *DebugComponent) restoreDeployment() error {
 		return fmt.Errorf("no rollback manifest available")
 	}
 
-	switch d.resourceType {
-	case Deployment:
-		deployment := &appsV1.Deployment{}
-		if err := json.Unmarshal([]byte(snapshot), deployment); err != nil {
-			return err
-		}
-
-		_, err = d.kubernetesClient.UpdateDeployment(deployment.GetNamespace(), deployment)
-		return err
-	case StatefulSet:
-		statefulSet := &appsV1.StatefulSet{}
-		if err := json.Unmarshal([]byte(snapshot), statefulSet); err != nil {
-			return err
-		}
-
-		_, err = d.kubernetesClient.UpdateStatefulSet(statefulSet.GetNamespace(), statefulSet)
-		return err
-	case DaemonSet:
-		daemonSet := &appsV1.DaemonSet{}
-		if err := json.Unmarshal([]byte(snapshot), daemonSet); err != nil {
-			return err
-		}
-
-		_, err = d.kubernetesClient.UpdateDaemonSet(daemonSet.GetNamespace(), daemonSet)
-		return err
-	default:
-		return d.resourceTypeNotSupportedError()
-	}
-}
-
-func (d *DebugComponent) getResourceManifest() ([]byte, error) {
-	resource, err := d.getResource()
-	if err != nil {
-		return nil, err
-	}
-
-	return json.Marshal(resource)
+	return resource.Restore(ctx, snapshot)
 }
 
 func (d *DebugComponent) getCurrentManifestSnapshot() (string, error) {
-	fullSnapshot, err := d.getResourceManifest()
+	resource, err := d.getResource()
 	if err != nil {
 		return "", err
 	}
 
-	var snapshot []byte
-	switch d.resourceType {
-	case Deployment:
-		applyResource := &appsCoreV1.DeploymentApplyConfiguration{}
-		if err := json.Unmarshal(fullSnapshot, applyResource); err != nil {
-			return "", err
-		}
-
-		// strip unnecessary data
-		applyResource.WithStatus(nil)
-		applyResource.Generation = nil
-		applyResource.UID = nil
-		applyResource.ResourceVersion = nil
-		annotations := make(map[string]string)
-		for key, value := range applyResource.Annotations {
-			if key == MetadataK8SRevision || key == MetadataKubeCTLLastAppliedConf {
-				continue
-			}
-
-			annotations[key] = value
-		}
-		applyResource.Annotations = annotations
-
-		snapshot, err = json.Marshal(applyResource)
-		if err != nil {
-			return "", err
-		}
-	case StatefulSet:
-		applyResource := &appsCoreV1.StatefulSetApplyConfiguration{}
-		if err := json.Unmarshal(fullSnapshot, applyResource); err != nil {
-			return "", err
-		}
-
-		// strip unnecessary data
-		applyResource.WithStatus(nil)
-		applyResource.Generation = nil
-		applyResource.UID = nil
-		applyResource.ResourceVersion = nil
-		annotations := make(map[string]string)
-		for key, value := range applyResource.Annotations {
-			if key == MetadataK8SRevision || key == MetadataKubeCTLLastAppliedConf {
-				continue
-			}
-
-			annotations[key] = value
-		}
-		applyResource.Annotations = annotations
-
-		snapshot, err = json.Marshal(applyResource)
-		if err != nil {
-			return "", err
-		}
-	case DaemonSet:
-		applyResource := &appsCoreV1.DaemonSetApplyConfiguration{}
-		if err := json.Unmarshal(fullSnapshot, applyResource); err != nil {
-			return "", err
-		}
-
-		// strip unnecessary data
-		applyResource.WithStatus(nil)
-		applyResource.Generation = nil
-		applyResource.UID = nil
-		applyResource.ResourceVersion = nil
-		annotations := make(map[string]string)
-		for key, value := range applyResource.Annotations {
-			if key == MetadataK8SRevision || key == MetadataKubeCTLLastAppliedConf {
-				continue
-			}
-
-			annotations[key] = value
-		}
-		applyResource.Annotations = annotations
-
-		snapshot, err = json.Marshal(applyResource)
-		if err != nil {
-			return "", err
-		}
-	default:
-		return "", d.resourceTypeNotSupportedError()
-	}
-
-	return string(snapshot), nil
+	return resource.GetSnapshot()
 }
 
-func (d *DebugComponent) preparePodTemplateSpec(podTemplateSpec *applyCoreV1.PodTemplateSpecApplyConfiguration) error {
+func (d *DebugComponent) preparePodTemplateSpec(ctx context.Context, podTemplateSpec *applyCoreV1.PodTemplateSpecApplyConfiguration) error {
 	resource, err := d.getResource()
 	if err != nil {
 		return err
@@ -357,14 +138,17 @@ func (d *DebugComponent) preparePodTemplateSpec(podTemplateSpec *applyCoreV1.Pod
 		WithAnnotations(podAnnotations).
 		WithLabels(podLabels)
 
-	return d.preparePodSpec(podTemplateSpec)
+	return d.preparePodSpec(ctx, podTemplateSpec)
 }
 
-func (d *DebugComponent) preparePodSpec(podTemplateSpec *applyCoreV1.PodTemplateSpecApplyConfiguration) error {
+func (d *DebugComponent) preparePodSpec(ctx context.Context, podTemplateSpec *applyCoreV1.PodTemplateSpecApplyConfiguration) error {
 	podSpec := applyCoreV1.PodSpec()
 
+	podSpec.WithVolumes(applyCoreV1.Volume().
+		WithName(VolumeNameSession).
+		WithEmptyDir(applyCoreV1.EmptyDirVolumeSource()))
 
-	if err := d.prepareContainer(podSpec); err != nil {
+	if err := d.prepareContainer(ctx, podSpec); err != nil {
 		return err
 	}
 
@@ -373,10 +157,23 @@ func (d *DebugComponent) preparePodSpec(podTemplateSpec *applyCoreV1.PodTemplate
 	return nil
 }
 
-func (d *DebugComponent) prepareContainer(podSpec *applyCoreV1.PodSpecApplyConfiguration) error {
+func (d *DebugComponent) prepareContainer(ctx context.Context, podSpec *applyCoreV1.PodSpecApplyConfiguration) error {
+	command := []string{"sh", "-c", d.getKeepAliveScript()}
+	if d.crashCapture {
+		crashCaptureCommand, err := d.getCrashCaptureCommand(ctx)
+		if err != nil {
+			return err
+		}
+
+		command = crashCaptureCommand
+	}
+
 	container := applyCoreV1.Container().
 		WithName(d.container.Name).
-		WithCommand("sh", "-c", "tail -f /dev/null")
+		WithCommand(command...).
+		WithVolumeMounts(applyCoreV1.VolumeMount().
+			WithName(VolumeNameSession).
+			WithMountPath(SessionDir))
 
 	if !d.isInitContainer {
 	    nullProbe := d.getNullProbeApplyConfiguration()
@@ -398,6 +195,15 @@ func (d *DebugComponent) prepareContainer(podSpec *applyCoreV1.PodSpecApplyConfi
 	return nil
 }
 
+// getKeepAliveScript blocks regular containers forever and init containers until they are marked as done
+func (d *DebugComponent) getKeepAliveScript() string {
+	if d.isInitContainer {
+		return fmt.Sprintf("while [ ! -f %s ]; do sleep 1; done; exit 0", InitContainerDoneFile)
+	}
+
+	return "exec tail -f /dev/null"
+}
+
 func (d *DebugComponent) getNullProbeApplyConfiguration() *applyCoreV1.ProbeApplyConfiguration {
 	return applyCoreV1.Probe().
 		WithExec(applyCoreV1.ExecAction().WithCommand("true")).
@@ -405,19 +211,15 @@ func (d *DebugComponent) getNullProbeApplyConfiguration() *applyCoreV1.ProbeAppl
 }
 
 func (d *DebugComponent) getResourceSelector() (*apiMetaV1.LabelSelector, error) {
-	switch d.resourceType {
-	case Deployment:
-		return d.deployment.Spec.Selector, nil
-	case StatefulSet:
-		return d.statefulSet.Spec.Selector, nil
-	case DaemonSet:
-		return d.daemonSet.Spec.Selector, nil
-	default:
-		return nil, d.resourceTypeNotSupportedError()
+	resource, err := d.getResource()
+	if err != nil {
+		return nil, err
 	}
+
+	return resource.GetSelector()
 }
 
-func (d *DebugComponent) waitPodReady() error {
+func (d *DebugComponent) waitPodReady(ctx context.Context) error {
 	d.StartSpinner(" Waiting for pod to be ready")
 	defer d.StopSpinner()
 
@@ -431,132 +233,62 @@ func (d *DebugComponent) waitPodReady() error {
 		return err
 	}
 
-	namespace := resource.GetNamespace()
-	labelSelector := apiMetaV1.LabelSelector{MatchLabels: resourceSelector.MatchLabels}
-	listOptions := apiMetaV1.ListOptions{
-		LabelSelector: labels.Set(labelSelector.MatchLabels).String(),
+	_, err = workload.WaitPod(ctx, d.kubernetesClient, resource.GetNamespace(), resourceSelector, d.waitTimeout, d.isDebugPodReady)
+	if errors.Is(err, workload.ErrPodWaitTimeout) {
+		return fmt.Errorf("pod not ready for debugging")
 	}
 
-	startTimestamp := time.Now().Unix()
-	for {
-		time.Sleep(1 * time.Second)
-		podList, err := d.kubernetesClient.ListPods(namespace, listOptions)
-		if err != nil {
-			return err
-		}
+	return err
+}
 
-		for _, pod := range podList.Items {
-		    if d.isInitContainer {
-		        if pod.DeletionTimestamp != nil || pod.Status.Phase != coreV1.PodPending {
-                    continue
-                }
-
-                for _, containerStatus := range pod.Status.InitContainerStatuses {
-                    if containerStatus.Name == d.container.Name && containerStatus.Started != nil && *containerStatus.Started {
-                        return nil
-                    }
-                }
-		    } else {
-                if pod.DeletionTimestamp != nil || pod.Status.Phase != coreV1.PodRunning {
-                    continue
-                }
-
-                for _, containerStatus := range pod.Status.ContainerStatuses {
-                    if containerStatus.Name == d.container.Name && containerStatus.Ready {
-                        return nil
-                    }
-                }
-            }
-		}
+// isDebugPodReady waits for the debugged init container to start, or for the debugged container to be ready
+func (d *DebugComponent) isDebugPodReady(pod *coreV1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
 
-		nowTimestamp := time.Now().Unix()
-		if nowTimestamp-startTimestamp >= d.waitTimeout {
-			break
+	if d.isInitContainer {
+		if pod.Status.Phase != coreV1.PodPending {
+			return false
 		}
-	}
 
-	// timeout reached
-	return fmt.Errorf("pod not ready for debugging")
-}
+		for _, containerStatus := range pod.Status.InitContainerStatuses {
+			if containerStatus.Name == d.container.Name && containerStatus.Started != nil && *containerStatus.Started {
+				return true
+			}
+		}
 
-func (d *DebugComponent) getResourceContainers() ([]coreV1.Container, error) {
-	switch d.resourceType {
-	case Deployment:
-		return k8sTools.GetDeploymentContainers(d.deployment), nil
-	case StatefulSet:
-		return k8sTools.GetStatefulSetContainers(d.statefulSet), nil
-	case DaemonSet:
-		return k8sTools.GetDaemonSetContainers(d.daemonSet), nil
-	default:
-		return []coreV1.Container{}, d.resourceTypeNotSupportedError()
+		return false
 	}
-}
 
-func (d *DebugComponent) getResourceContainer(containerName string) (*coreV1.Container, error) {
-	switch d.resourceType {
-	case Deployment:
-		return k8sTools.GetDeploymentContainerByName(d.deployment, containerName)
-	case StatefulSet:
-		return k8sTools.GetStatefulSetContainerByName(d.statefulSet, containerName)
-	case DaemonSet:
-		return k8sTools.GetDaemonSetContainerByName(d.daemonSet, containerName)
-	default:
-		return nil, ErrNoResourceSelected
+	if pod.Status.Phase != coreV1.PodRunning {
+		return false
 	}
-}
 
-func (d *DebugComponent) getResourceInitContainers() ([]coreV1.Container, error) {
-	switch d.resourceType {
-	case Deployment:
-		return k8sTools.GetDeploymentInitContainers(d.deployment), nil
-	case StatefulSet:
-		return k8sTools.GetStatefulSetInitContainers(d.statefulSet), nil
-	case DaemonSet:
-		return k8sTools.GetDaemonSetInitContainers(d.daemonSet), nil
-	default:
-		return []coreV1.Container{}, d.resourceTypeNotSupportedError()
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name == d.container.Name && containerStatus.Ready {
+			return true
+		}
 	}
-}
 
-func (d *DebugComponent) getResourceInitContainer(containerName string) (*coreV1.Container, error) {
-	switch d.resourceType {
-	case Deployment:
-		return k8sTools.GetDeploymentInitContainerByName(d.deployment, containerName)
-	case StatefulSet:
-		return k8sTools.GetStatefulSetInitContainerByName(d.statefulSet, containerName)
-	case DaemonSet:
-		return k8sTools.GetDaemonSetInitContainerByName(d.daemonSet, containerName)
-	default:
-		return nil, ErrNoResourceSelected
-	}
+	return false
 }
 
-func (d *DebugComponent) getContainerIndex() (int, bool, error) {
-	if d.container == nil {
-		return -1, false, fmt.Errorf("%w: %s", ErrContainerNotFound, "<nil>")
-	}
-
-	containers, err := d.getResourceContainers()
+func (d *DebugComponent) getResourceContainers() ([]coreV1.Container, error) {
+	resource, err := d.getResource()
 	if err != nil {
-		return -1, false, err
-	}
-
-	for i, container := range containers {
-		if container.Name == d.container.Name {
-			return i, false, nil
-		}
+		return nil, err
 	}
 
-    initContainers, err := d.getResourceInitContainers()
-    if err != nil {
-        return -1, true, err
-    }
+	return resource.GetContainers()
+}
 
-    for i, initContainer := range initContainers {
-        if initContainer.Name == d.container.Name {
-            return i, true, nil
-        }
-    }
+func (d *DebugComponent) getResourceInitContainers() ([]coreV1.Container, error) {
+	resource, err := d.getResource()
+	if err != nil {
+		return nil, err
+	}
 
-	return -1, false, fmt.Errorf("%w: %s", ErrContainerNotFound, d.container.Name)
+	return resource.GetInitContainers()
 }
+
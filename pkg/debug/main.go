@@ -1,14 +1,26 @@
 package debug
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/signal"
+	"time"
 
-	"bunnyshell.com/dev/pkg/util"
+	"k8s.io/client-go/tools/remotecommand"
 )
 
+// rollbackTimeout bounds the rollback of a failed Up, which runs after a cancellation too
+const rollbackTimeout = 2 * time.Minute
+
 func (d *DebugComponent) CanUp(forceRecreateResource bool) error {
+	// ephemeral containers leave the workload untouched
+	if d.ephemeral {
+		d.shouldPrepareResource = false
+
+		return nil
+	}
+
     resource, err := d.getResource()
    	if err != nil {
    		return err
@@ -48,39 +60,105 @@ func (d *DebugComponent) CanUp(forceRecreateResource bool) error {
 }
 
 func (d *DebugComponent) Up() error {
+	ctx := d.getContext()
+
+	if d.ephemeral {
+		return d.startEphemeralContainer(ctx)
+	}
+
     if (d.shouldPrepareResource) {
-        if err := d.prepareResource(); err != nil {
+        if err := d.prepareResource(ctx); err != nil {
             return err
         }
     } else {
         fmt.Print("Skip recreating Pod\n")
     }
 
-	if err := d.waitPodReady(); err != nil {
+	if err := d.waitPodReady(ctx); err != nil {
+		if !d.shouldPrepareResource {
+			return err
+		}
+
+		if rollbackErr := d.rollbackUp(); rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("cannot roll back: %w", rollbackErr))
+		}
+
 		return err
 	}
 
+	if d.isInitContainer {
+		fmt.Printf("Create %s inside the init container to let the pod continue with the next containers\n", InitContainerDoneFile)
+	}
+
 	return nil
 }
 
+// rollbackUp restores the resource prepared by a failed Up, with a context left uncancelled
+func (d *DebugComponent) rollbackUp() error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(d.getContext()), rollbackTimeout)
+	defer cancel()
+
+	// the rollback manifest is read from the live resource
+	resource, err := d.workload.Get(ctx)
+	if err != nil {
+		return err
+	}
+	d.workload = resource
+
+	return d.restoreDeployment(ctx)
+}
+
 func (d *DebugComponent) Down() error {
-	if err := d.restoreDeployment(); err != nil {
+	if d.ephemeral {
+		return nil
+	}
+
+	if err := d.restoreDeployment(d.getContext()); err != nil {
 		return err
 	}
 
     return nil
 }
 
+func (d *DebugComponent) StartTerminal() error {
+	ctx := d.getContext()
+
+	if d.ephemeral {
+		return d.attachEphemeralContainer(ctx)
+	}
+
+	return d.execShell(ctx)
+}
+
+// CompleteInitContainer lets the debugged init container exit successfully, so the pod proceeds
+// with the next init containers and then the main containers
+func (d *DebugComponent) CompleteInitContainer() error {
+	ctx := d.getContext()
+
+	if !d.isInitContainer {
+		return fmt.Errorf("container %s is not an init container", d.container.Name)
+	}
+
+	pod, err := d.getDebugPod(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the shell, already needed by the keep alive script, creates the file without relying on touch
+	return d.kubernetesClient.Exec(ctx, pod, d.container.Name, []string{"sh", "-c", fmt.Sprintf(": > %s", InitContainerDoneFile)}, remotecommand.StreamOptions{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	})
+}
+
+// Wait blocks until the session is closed, or closes it when the context is cancelled
 func (d *DebugComponent) Wait() error {
-	// close channels on cli signal interrupt
-	signalTermination := make(chan os.Signal, 1)
-	signal.Notify(signalTermination, util.TerminationSignals...)
-	defer signal.Stop(signalTermination)
+	ctx := d.getContext()
 
 	select {
-	case sig := <-signalTermination:
+	case <-ctx.Done():
 		d.Close()
-		return fmt.Errorf("terminated by signal: %s", sig)
+		return context.Cause(ctx)
 	case <-d.stopChannel:
 		return nil
 	}
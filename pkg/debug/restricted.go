@@ -0,0 +1,77 @@
+package debug
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// DefaultRestrictedInitContainers are injected by the platform, service meshes and secret injectors
+var DefaultRestrictedInitContainers = []string{
+	"bns-volume-permissions",
+	"init-shared-path-*",
+	"istio-init",
+	"istio-validation",
+	"istio-proxy",
+	"linkerd-init",
+	"linkerd-network-validator",
+	"linkerd-proxy",
+	"vault-agent-init",
+	"consul-connect-inject-init",
+}
+
+type containerNameRule struct {
+	glob   string
+	regexp *regexp.Regexp
+}
+
+// newContainerNameRule parses rules wrapped in slashes as regular expressions and the rest as globs
+func newContainerNameRule(rule string) (*containerNameRule, error) {
+	if len(rule) > 1 && strings.HasPrefix(rule, "/") && strings.HasSuffix(rule, "/") {
+		exp, err := regexp.Compile(rule[1 : len(rule)-1])
+		if err != nil {
+			return nil, err
+		}
+
+		return &containerNameRule{regexp: exp}, nil
+	}
+
+	if _, err := path.Match(rule, ""); err != nil {
+		return nil, err
+	}
+
+	return &containerNameRule{glob: rule}, nil
+}
+
+func (r *containerNameRule) Matches(name string) bool {
+	if r.regexp != nil {
+		return r.regexp.MatchString(name)
+	}
+
+	matches, _ := path.Match(r.glob, name)
+	return matches
+}
+
+func newContainerNameRules(rules []string) ([]*containerNameRule, error) {
+	result := make([]*containerNameRule, 0, len(rules))
+	for _, rule := range rules {
+		containerNameRule, err := newContainerNameRule(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, containerNameRule)
+	}
+
+	return result, nil
+}
+
+func (d *DebugComponent) isRestrictedInitContainer(name string) bool {
+	for _, rule := range d.restrictedInitContainers {
+		if rule.Matches(name) {
+			return true
+		}
+	}
+
+	return false
+}
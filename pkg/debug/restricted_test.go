@@ -0,0 +1,29 @@
+package debug
+
+import (
+	"testing"
+)
+
+func TestRestrictedInitContainers(t *testing.T) {
+	rules, err := newContainerNameRules(append(DefaultRestrictedInitContainers, "/^migrate-[0-9]+$/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &DebugComponent{restrictedInitContainers: rules}
+	for name, restricted := range map[string]bool{
+		"istio-init":            true,
+		"init-shared-path-data": true,
+		"init-shared-path":      false,
+		"migrate-42":            true,
+		"migrate-latest":        false,
+	} {
+		if actual := d.isRestrictedInitContainer(name); actual != restricted {
+			t.Errorf("%s: expected %t, got %t", name, restricted, actual)
+		}
+	}
+
+	if _, err := newContainerNameRules([]string{"init-[0-9"}); err == nil {
+		t.Error("expected an error for an invalid glob")
+	}
+}
@@ -0,0 +1,118 @@
+package debug
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/k8s/workload"
+
+	"golang.org/x/term"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// prefer bash, falling back to sh for minimal images
+var shellCommand = []string{
+	"/bin/sh",
+	"-c",
+	"command -v bash >/dev/null 2>&1 && exec bash || exec /bin/sh",
+}
+
+func (d *DebugComponent) execShell(ctx context.Context) error {
+	pod, err := d.getDebugPod(ctx)
+	if err != nil {
+		return err
+	}
+
+	return d.streamTerminal(func(streamOptions remotecommand.StreamOptions) error {
+		return d.kubernetesClient.Exec(ctx, pod, d.container.Name, shellCommand, streamOptions)
+	})
+}
+
+// streamTerminal connects the local terminal, in raw mode and with resize events, to a remote stream
+func (d *DebugComponent) streamTerminal(stream func(streamOptions remotecommand.StreamOptions) error) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return stream(remotecommand.StreamOptions{
+			Stdin:  os.Stdin,
+			Stdout: os.Stdout,
+			Stderr: os.Stderr,
+		})
+	}
+
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, oldState)
+
+	sizeQueue := k8s.NewTerminalSizeQueue(fd)
+	defer sizeQueue.Stop()
+
+	return stream(remotecommand.StreamOptions{
+		Stdin:             os.Stdin,
+		Stdout:            os.Stdout,
+		Tty:               true,
+		TerminalSizeQueue: sizeQueue,
+	})
+}
+
+// getDebugPod returns the pod where the selected container or init container is running, or any live pod
+// for ephemeral containers, which also debug crashing containers
+func (d *DebugComponent) getDebugPod(ctx context.Context) (*coreV1.Pod, error) {
+	resource, err := d.getResource()
+	if err != nil {
+		return nil, err
+	}
+
+	resourceSelector, err := d.getResourceSelector()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := d.isDebugContainerRunning
+	if d.ephemeral {
+		filter = isEphemeralTarget
+	}
+
+	pod, err := workload.FindPod(ctx, d.kubernetesClient, resource.GetNamespace(), resourceSelector, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if pod != nil {
+		return pod.DeepCopy(), nil
+	}
+
+	return nil, fmt.Errorf("pod not found for component %v", resource.GetName())
+}
+
+func (d *DebugComponent) isDebugContainerRunning(pod *coreV1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+
+	containerStatuses := pod.Status.ContainerStatuses
+	if d.isInitContainer {
+		containerStatuses = pod.Status.InitContainerStatuses
+	}
+
+	for _, containerStatus := range containerStatuses {
+		if containerStatus.Name == d.container.Name && containerStatus.State.Running != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isEphemeralTarget accepts the scheduled pods which are not terminating, whatever the state of their containers
+func isEphemeralTarget(pod *coreV1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Spec.NodeName == "" {
+		return false
+	}
+
+	return pod.Status.Phase == coreV1.PodRunning || pod.Status.Phase == coreV1.PodPending
+}
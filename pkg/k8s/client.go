@@ -6,30 +6,44 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 
 	"bunnyshell.com/dev/pkg/util"
 
 	appsV1 "k8s.io/api/apps/v1"
+	authorizationV1 "k8s.io/api/authorization/v1"
+	autoscalingV2 "k8s.io/api/autoscaling/v2"
+	batchV1 "k8s.io/api/batch/v1"
 	coreV1 "k8s.io/api/core/v1"
+	storageV1 "k8s.io/api/storage/v1"
 	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/httpstream"
 	"k8s.io/apimachinery/pkg/watch"
 	applyCoreV1 "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/tools/remotecommand"
 	"k8s.io/client-go/transport/spdy"
 )
 
 const (
-	PortForwardMethod = "POST"
+	PortForwardMethod   = "POST"
+	RemoteCommandMethod = "POST"
+
+	RemoteCommandWebSocketMethod = "GET"
 
 	BunnyshellRemoteDevFieldManager = "bunnyshell-dev"
 )
 
+var ErrAPIGroupNotFound = fmt.Errorf("api group not found")
+
 type PortForwardOptions struct {
 	Interface string
 
@@ -53,150 +67,260 @@ func NewPortForwardOptions(iface string, remotePort, localPort int) *PortForward
 }
 
 type KubernetesClient struct {
-	kubeConfigPath string
-
 	config     clientcmd.ClientConfig
 	restConfig *rest.Config
 	clientSet  *kubernetes.Clientset
+
+	dynamicClient *dynamic.DynamicClient
+
+	// dryRun sends every write with dryRun=All, so the API server validates it without persisting it
+	dryRun bool
 }
 
+// NewKubernetesClient loads the kubeconfig file alone
+//
+// Deprecated: use NewKubernetesClientFromOptions
 func NewKubernetesClient(kubeConfigPath string) (*KubernetesClient, error) {
-	newKubernetes := new(KubernetesClient)
+	return NewKubernetesClientFromOptions(&KubeConfigOptions{KubeConfigPath: kubeConfigPath})
+}
 
-	kubeconfig, err := os.ReadFile(kubeConfigPath)
-	if err != nil {
-		return newKubernetes, err
-	}
+func NewKubernetesClientFromOptions(options *KubeConfigOptions) (*KubernetesClient, error) {
+	newKubernetes := new(KubernetesClient)
 
-	config, err := clientcmd.NewClientConfigFromBytes(kubeconfig)
+	config := options.GetClientConfig()
+	restConfig, err := config.ClientConfig()
 	if err != nil {
 		return newKubernetes, err
 	}
 
-	restConfig, err := config.ClientConfig()
+	clientset, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
 		return newKubernetes, err
 	}
 
-	clientset, err := kubernetes.NewForConfig(restConfig)
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
 	if err != nil {
 		return newKubernetes, err
 	}
 
-	newKubernetes.kubeConfigPath = kubeConfigPath
 	newKubernetes.config = config
 	newKubernetes.restConfig = restConfig
 	newKubernetes.clientSet = clientset
+	newKubernetes.dynamicClient = dynamicClient
 
 	return newKubernetes, nil
 }
 
+// WithDryRun makes the following writes server-side dry runs
+func (k *KubernetesClient) WithDryRun(dryRun bool) *KubernetesClient {
+	k.dryRun = dryRun
+	return k
+}
+
+func (k *KubernetesClient) getDryRun() []string {
+	if !k.dryRun {
+		return nil
+	}
+
+	return []string{apiMetaV1.DryRunAll}
+}
+
 func (k *KubernetesClient) GetKubeConfigNamespace() (string, error) {
 	namespace, _, err := k.config.Namespace()
 	return namespace, err
 }
 
-func (k *KubernetesClient) UpdateDeployment(namespace string, deployment *appsV1.Deployment) (*appsV1.Deployment, error) {
-	return k.clientSet.AppsV1().Deployments(namespace).Update(context.TODO(), deployment, apiMetaV1.UpdateOptions{})
+func (k *KubernetesClient) UpdateDeployment(ctx context.Context, namespace string, deployment *appsV1.Deployment) (*appsV1.Deployment, error) {
+	return k.clientSet.AppsV1().Deployments(namespace).Update(ctx, deployment, apiMetaV1.UpdateOptions{DryRun: k.getDryRun()})
+}
+
+func (k *KubernetesClient) UpdateStatefulSet(ctx context.Context, namespace string, statefulSet *appsV1.StatefulSet) (*appsV1.StatefulSet, error) {
+	return k.clientSet.AppsV1().StatefulSets(namespace).Update(ctx, statefulSet, apiMetaV1.UpdateOptions{DryRun: k.getDryRun()})
+}
+
+func (k *KubernetesClient) UpdateDaemonSet(ctx context.Context, namespace string, daemonSet *appsV1.DaemonSet) (*appsV1.DaemonSet, error) {
+	return k.clientSet.AppsV1().DaemonSets(namespace).Update(ctx, daemonSet, apiMetaV1.UpdateOptions{DryRun: k.getDryRun()})
+}
+
+func (k *KubernetesClient) CreateDeployment(ctx context.Context, namespace string, deployment *appsV1.Deployment) (*appsV1.Deployment, error) {
+	return k.clientSet.AppsV1().Deployments(namespace).Create(ctx, deployment, apiMetaV1.CreateOptions{FieldManager: BunnyshellRemoteDevFieldManager, DryRun: k.getDryRun()})
 }
 
-func (k *KubernetesClient) UpdateStatefulSet(namespace string, statefulSet *appsV1.StatefulSet) (*appsV1.StatefulSet, error) {
-	return k.clientSet.AppsV1().StatefulSets(namespace).Update(context.TODO(), statefulSet, apiMetaV1.UpdateOptions{})
+func (k *KubernetesClient) CreateStatefulSet(ctx context.Context, namespace string, statefulSet *appsV1.StatefulSet) (*appsV1.StatefulSet, error) {
+	return k.clientSet.AppsV1().StatefulSets(namespace).Create(ctx, statefulSet, apiMetaV1.CreateOptions{FieldManager: BunnyshellRemoteDevFieldManager, DryRun: k.getDryRun()})
 }
 
-func (k *KubernetesClient) UpdateDaemonSet(namespace string, daemonSet *appsV1.DaemonSet) (*appsV1.DaemonSet, error) {
-	return k.clientSet.AppsV1().DaemonSets(namespace).Update(context.TODO(), daemonSet, apiMetaV1.UpdateOptions{})
+func (k *KubernetesClient) CreateDaemonSet(ctx context.Context, namespace string, daemonSet *appsV1.DaemonSet) (*appsV1.DaemonSet, error) {
+	return k.clientSet.AppsV1().DaemonSets(namespace).Create(ctx, daemonSet, apiMetaV1.CreateOptions{FieldManager: BunnyshellRemoteDevFieldManager, DryRun: k.getDryRun()})
 }
 
-func (k *KubernetesClient) ListNamespaces() (*coreV1.NamespaceList, error) {
-	return k.clientSet.CoreV1().Namespaces().List(context.TODO(), apiMetaV1.ListOptions{})
+func (k *KubernetesClient) DeleteDeployment(ctx context.Context, namespace, name string) error {
+	return k.clientSet.AppsV1().Deployments(namespace).Delete(ctx, name, apiMetaV1.DeleteOptions{DryRun: k.getDryRun()})
 }
 
-func (k *KubernetesClient) ListDeployments(namespace string) (*appsV1.DeploymentList, error) {
-	return k.clientSet.AppsV1().Deployments(namespace).List(context.TODO(), apiMetaV1.ListOptions{})
+func (k *KubernetesClient) DeleteStatefulSet(ctx context.Context, namespace, name string) error {
+	return k.clientSet.AppsV1().StatefulSets(namespace).Delete(ctx, name, apiMetaV1.DeleteOptions{DryRun: k.getDryRun()})
 }
 
-func (k *KubernetesClient) ListStatefulSets(namespace string) (*appsV1.StatefulSetList, error) {
-	return k.clientSet.AppsV1().StatefulSets(namespace).List(context.TODO(), apiMetaV1.ListOptions{})
+func (k *KubernetesClient) DeleteDaemonSet(ctx context.Context, namespace, name string) error {
+	return k.clientSet.AppsV1().DaemonSets(namespace).Delete(ctx, name, apiMetaV1.DeleteOptions{DryRun: k.getDryRun()})
 }
 
-func (k *KubernetesClient) ListDaemonSets(namespace string) (*appsV1.DaemonSetList, error) {
-	return k.clientSet.AppsV1().DaemonSets(namespace).List(context.TODO(), apiMetaV1.ListOptions{})
+func (k *KubernetesClient) ListNamespaces(ctx context.Context) (*coreV1.NamespaceList, error) {
+	return k.clientSet.CoreV1().Namespaces().List(ctx, apiMetaV1.ListOptions{})
 }
 
-func (k *KubernetesClient) DeletePVC(namespace, name string) error {
-	return k.clientSet.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), name, apiMetaV1.DeleteOptions{})
+func (k *KubernetesClient) ListDeployments(ctx context.Context, namespace string) (*appsV1.DeploymentList, error) {
+	return k.clientSet.AppsV1().Deployments(namespace).List(ctx, apiMetaV1.ListOptions{})
 }
 
-func (k *KubernetesClient) DeleteSecret(namespace, name string) error {
-	return k.clientSet.CoreV1().Secrets(namespace).Delete(context.TODO(), name, apiMetaV1.DeleteOptions{})
+func (k *KubernetesClient) ListStatefulSets(ctx context.Context, namespace string) (*appsV1.StatefulSetList, error) {
+	return k.clientSet.AppsV1().StatefulSets(namespace).List(ctx, apiMetaV1.ListOptions{})
 }
 
-func (k *KubernetesClient) GetNamespace(name string) (*coreV1.Namespace, error) {
-	return k.clientSet.CoreV1().Namespaces().Get(context.TODO(), name, apiMetaV1.GetOptions{})
+func (k *KubernetesClient) ListDaemonSets(ctx context.Context, namespace string) (*appsV1.DaemonSetList, error) {
+	return k.clientSet.AppsV1().DaemonSets(namespace).List(ctx, apiMetaV1.ListOptions{})
 }
 
-func (k *KubernetesClient) GetDeployment(namespace, deploymentName string) (*appsV1.Deployment, error) {
-	return k.clientSet.AppsV1().Deployments(namespace).Get(context.TODO(), deploymentName, apiMetaV1.GetOptions{})
+func (k *KubernetesClient) DeletePVC(ctx context.Context, namespace, name string) error {
+	return k.clientSet.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, name, apiMetaV1.DeleteOptions{DryRun: k.getDryRun()})
 }
 
-func (k *KubernetesClient) GetStatefulSet(namespace, name string) (*appsV1.StatefulSet, error) {
-	return k.clientSet.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, apiMetaV1.GetOptions{})
+func (k *KubernetesClient) DeleteSecret(ctx context.Context, namespace, name string) error {
+	return k.clientSet.CoreV1().Secrets(namespace).Delete(ctx, name, apiMetaV1.DeleteOptions{DryRun: k.getDryRun()})
 }
 
-func (k *KubernetesClient) GetDaemonSet(namespace, name string) (*appsV1.DaemonSet, error) {
-	return k.clientSet.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, apiMetaV1.GetOptions{})
+func (k *KubernetesClient) GetServerVersion() (string, error) {
+	version, err := k.clientSet.Discovery().ServerVersion()
+	if err != nil {
+		return "", err
+	}
+
+	return version.GitVersion, nil
+}
+
+// CanI asks the API server whether the current user is allowed to perform the action
+func (k *KubernetesClient) CanI(ctx context.Context, attributes *authorizationV1.ResourceAttributes) (*authorizationV1.SubjectAccessReviewStatus, error) {
+	review := &authorizationV1.SelfSubjectAccessReview{
+		Spec: authorizationV1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: attributes,
+		},
+	}
+
+	response, err := k.clientSet.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, apiMetaV1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Status, nil
 }
 
-func (k *KubernetesClient) ApplySecret(secret *applyCoreV1.SecretApplyConfiguration) error {
+func (k *KubernetesClient) ListStorageClasses(ctx context.Context) (*storageV1.StorageClassList, error) {
+	return k.clientSet.StorageV1().StorageClasses().List(ctx, apiMetaV1.ListOptions{})
+}
+
+func (k *KubernetesClient) GetPVC(ctx context.Context, namespace, name string) (*coreV1.PersistentVolumeClaim, error) {
+	return k.clientSet.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, apiMetaV1.GetOptions{})
+}
+
+func (k *KubernetesClient) GetSecret(ctx context.Context, namespace, name string) (*coreV1.Secret, error) {
+	return k.clientSet.CoreV1().Secrets(namespace).Get(ctx, name, apiMetaV1.GetOptions{})
+}
+
+func (k *KubernetesClient) GetNamespace(ctx context.Context, name string) (*coreV1.Namespace, error) {
+	return k.clientSet.CoreV1().Namespaces().Get(ctx, name, apiMetaV1.GetOptions{})
+}
+
+func (k *KubernetesClient) GetDeployment(ctx context.Context, namespace, deploymentName string) (*appsV1.Deployment, error) {
+	return k.clientSet.AppsV1().Deployments(namespace).Get(ctx, deploymentName, apiMetaV1.GetOptions{})
+}
+
+func (k *KubernetesClient) GetStatefulSet(ctx context.Context, namespace, name string) (*appsV1.StatefulSet, error) {
+	return k.clientSet.AppsV1().StatefulSets(namespace).Get(ctx, name, apiMetaV1.GetOptions{})
+}
+
+func (k *KubernetesClient) GetDaemonSet(ctx context.Context, namespace, name string) (*appsV1.DaemonSet, error) {
+	return k.clientSet.AppsV1().DaemonSets(namespace).Get(ctx, name, apiMetaV1.GetOptions{})
+}
+
+func (k *KubernetesClient) ApplySecret(ctx context.Context, secret *applyCoreV1.SecretApplyConfiguration) error {
 	applyOptions := apiMetaV1.ApplyOptions{
 		FieldManager: BunnyshellRemoteDevFieldManager,
+		DryRun:       k.getDryRun(),
 	}
-	_, err := k.clientSet.CoreV1().Secrets(*secret.Namespace).Apply(context.TODO(), secret, applyOptions)
+	_, err := k.clientSet.CoreV1().Secrets(*secret.Namespace).Apply(ctx, secret, applyOptions)
 	return err
 }
 
-func (k *KubernetesClient) ApplyPVC(pvc *applyCoreV1.PersistentVolumeClaimApplyConfiguration) error {
+func (k *KubernetesClient) ApplyPVC(ctx context.Context, pvc *applyCoreV1.PersistentVolumeClaimApplyConfiguration) error {
 	applyOptions := apiMetaV1.ApplyOptions{
 		FieldManager: BunnyshellRemoteDevFieldManager,
+		DryRun:       k.getDryRun(),
 	}
-	_, err := k.clientSet.CoreV1().PersistentVolumeClaims(*pvc.Namespace).Apply(context.TODO(), pvc, applyOptions)
+	_, err := k.clientSet.CoreV1().PersistentVolumeClaims(*pvc.Namespace).Apply(ctx, pvc, applyOptions)
 	return err
 }
 
-func (k *KubernetesClient) PatchDeployment(namespace, name string, data []byte) error {
-	_, err := k.clientSet.AppsV1().Deployments(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, data, apiMetaV1.PatchOptions{})
+func (k *KubernetesClient) PatchDeployment(ctx context.Context, namespace, name string, data []byte) error {
+	_, err := k.clientSet.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, data, apiMetaV1.PatchOptions{DryRun: k.getDryRun()})
 	return err
 }
 
-func (k *KubernetesClient) PatchStatefulSet(namespace, name string, data []byte) error {
-	_, err := k.clientSet.AppsV1().StatefulSets(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, data, apiMetaV1.PatchOptions{})
+func (k *KubernetesClient) PatchStatefulSet(ctx context.Context, namespace, name string, data []byte) error {
+	_, err := k.clientSet.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, data, apiMetaV1.PatchOptions{DryRun: k.getDryRun()})
 	return err
 }
 
-func (k *KubernetesClient) PatchDaemonSet(namespace, name string, data []byte) error {
-	_, err := k.clientSet.AppsV1().DaemonSets(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, data, apiMetaV1.PatchOptions{})
+func (k *KubernetesClient) PatchDaemonSet(ctx context.Context, namespace, name string, data []byte) error {
+	_, err := k.clientSet.AppsV1().DaemonSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, data, apiMetaV1.PatchOptions{DryRun: k.getDryRun()})
 	return err
 }
 
-func (k *KubernetesClient) BatchPatchDeployment(namespace, name string, data []byte) error {
-	_, err := k.clientSet.AppsV1().Deployments(namespace).Patch(context.TODO(), name, types.JSONPatchType, data, apiMetaV1.PatchOptions{})
+func (k *KubernetesClient) ApplyDeployment(ctx context.Context, namespace, name string, data []byte, force, dryRun bool) error {
+	_, err := k.clientSet.AppsV1().Deployments(namespace).Patch(ctx, name, types.ApplyPatchType, data, k.getApplyPatchOptions(force, dryRun))
+	return err
+}
 
+func (k *KubernetesClient) ApplyStatefulSet(ctx context.Context, namespace, name string, data []byte, force, dryRun bool) error {
+	_, err := k.clientSet.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.ApplyPatchType, data, k.getApplyPatchOptions(force, dryRun))
 	return err
 }
 
-func (k *KubernetesClient) BatchPatchStatefulSet(namespace, name string, data []byte) error {
-	_, err := k.clientSet.AppsV1().StatefulSets(namespace).Patch(context.TODO(), name, types.JSONPatchType, data, apiMetaV1.PatchOptions{})
+func (k *KubernetesClient) ApplyDaemonSet(ctx context.Context, namespace, name string, data []byte, force, dryRun bool) error {
+	_, err := k.clientSet.AppsV1().DaemonSets(namespace).Patch(ctx, name, types.ApplyPatchType, data, k.getApplyPatchOptions(force, dryRun))
 	return err
 }
 
-func (k *KubernetesClient) BatchPatchDaemonSet(namespace, name string, data []byte) error {
-	_, err := k.clientSet.AppsV1().DaemonSets(namespace).Patch(context.TODO(), name, types.JSONPatchType, data, apiMetaV1.PatchOptions{})
+func (k *KubernetesClient) getApplyPatchOptions(force, dryRun bool) apiMetaV1.PatchOptions {
+	patchOptions := apiMetaV1.PatchOptions{
+		FieldManager: BunnyshellRemoteDevFieldManager,
+		Force:        &force,
+	}
+	if dryRun || k.dryRun {
+		patchOptions.DryRun = []string{apiMetaV1.DryRunAll}
+	}
+
+	return patchOptions
+}
+
+func (k *KubernetesClient) BatchPatchDeployment(ctx context.Context, namespace, name string, data []byte) error {
+	_, err := k.clientSet.AppsV1().Deployments(namespace).Patch(ctx, name, types.JSONPatchType, data, apiMetaV1.PatchOptions{DryRun: k.getDryRun()})
+
+	return err
+}
+
+func (k *KubernetesClient) BatchPatchStatefulSet(ctx context.Context, namespace, name string, data []byte) error {
+	_, err := k.clientSet.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.JSONPatchType, data, apiMetaV1.PatchOptions{DryRun: k.getDryRun()})
+	return err
+}
+
+func (k *KubernetesClient) BatchPatchDaemonSet(ctx context.Context, namespace, name string, data []byte) error {
+	_, err := k.clientSet.AppsV1().DaemonSets(namespace).Patch(ctx, name, types.JSONPatchType, data, apiMetaV1.PatchOptions{DryRun: k.getDryRun()})
 	return err
 }
 
-func (k *KubernetesClient) ListPods(namespace string, listOptions apiMetaV1.ListOptions) (*coreV1.PodList, error) {
-	return k.clientSet.CoreV1().Pods(namespace).List(context.TODO(), listOptions)
+func (k *KubernetesClient) ListPods(ctx context.Context, namespace string, listOptions apiMetaV1.ListOptions) (*coreV1.PodList, error) {
+	return k.clientSet.CoreV1().Pods(namespace).List(ctx, listOptions)
 }
 
 func (k *KubernetesClient) GetPortForwardSubresourceURL(pod *coreV1.Pod) *url.URL {
@@ -207,7 +331,7 @@ func (k *KubernetesClient) GetPortForwardSubresourceURL(pod *coreV1.Pod) *url.UR
 		SubResource("portforward").URL()
 }
 
-func (k *KubernetesClient) PortForward(pod *coreV1.Pod, portForwardOptions *PortForwardOptions) (*portforward.PortForwarder, error) {
+func (k *KubernetesClient) PortForward(ctx context.Context, pod *coreV1.Pod, portForwardOptions *PortForwardOptions) (*portforward.PortForwarder, error) {
 	transport, upgrader, err := spdy.RoundTripperFor(k.restConfig)
 	if err != nil {
 		return nil, err
@@ -251,11 +375,192 @@ func (k *KubernetesClient) PortForward(pod *coreV1.Pod, portForwardOptions *Port
 	case <-forwarder.Ready:
 	case err := <-errChan:
 		return nil, err
+	case <-ctx.Done():
+		forwarder.Close()
+		return nil, context.Cause(ctx)
 	}
 
 	return forwarder, nil
 }
 
-func (k *KubernetesClient) WatchPods(namespace string, listOptions apiMetaV1.ListOptions) (watch.Interface, error) {
-	return k.clientSet.CoreV1().Pods(namespace).Watch(context.TODO(), listOptions)
+func (k *KubernetesClient) WatchPods(ctx context.Context, namespace string, listOptions apiMetaV1.ListOptions) (watch.Interface, error) {
+	return k.clientSet.CoreV1().Pods(namespace).Watch(ctx, listOptions)
+}
+
+func (k *KubernetesClient) WatchDeployments(ctx context.Context, namespace string, listOptions apiMetaV1.ListOptions) (watch.Interface, error) {
+	return k.clientSet.AppsV1().Deployments(namespace).Watch(ctx, listOptions)
+}
+
+func (k *KubernetesClient) WatchStatefulSets(ctx context.Context, namespace string, listOptions apiMetaV1.ListOptions) (watch.Interface, error) {
+	return k.clientSet.AppsV1().StatefulSets(namespace).Watch(ctx, listOptions)
+}
+
+func (k *KubernetesClient) WatchDaemonSets(ctx context.Context, namespace string, listOptions apiMetaV1.ListOptions) (watch.Interface, error) {
+	return k.clientSet.AppsV1().DaemonSets(namespace).Watch(ctx, listOptions)
+}
+
+func (k *KubernetesClient) ListJobs(ctx context.Context, namespace string) (*batchV1.JobList, error) {
+	return k.clientSet.BatchV1().Jobs(namespace).List(ctx, apiMetaV1.ListOptions{})
+}
+
+func (k *KubernetesClient) GetJob(ctx context.Context, namespace, name string) (*batchV1.Job, error) {
+	return k.clientSet.BatchV1().Jobs(namespace).Get(ctx, name, apiMetaV1.GetOptions{})
+}
+
+func (k *KubernetesClient) CreateJob(ctx context.Context, namespace string, job *batchV1.Job) (*batchV1.Job, error) {
+	return k.clientSet.BatchV1().Jobs(namespace).Create(ctx, job, apiMetaV1.CreateOptions{FieldManager: BunnyshellRemoteDevFieldManager, DryRun: k.getDryRun()})
+}
+
+func (k *KubernetesClient) PatchJob(ctx context.Context, namespace, name string, data []byte) error {
+	_, err := k.clientSet.BatchV1().Jobs(namespace).Patch(ctx, name, types.StrategicMergePatchType, data, apiMetaV1.PatchOptions{DryRun: k.getDryRun()})
+	return err
+}
+
+// DeleteJob deletes the job pods too, which are orphaned by default
+func (k *KubernetesClient) DeleteJob(ctx context.Context, namespace, name string) error {
+	propagationPolicy := apiMetaV1.DeletePropagationBackground
+	return k.clientSet.BatchV1().Jobs(namespace).Delete(ctx, name, apiMetaV1.DeleteOptions{PropagationPolicy: &propagationPolicy, DryRun: k.getDryRun()})
+}
+
+func (k *KubernetesClient) ListCronJobs(ctx context.Context, namespace string) (*batchV1.CronJobList, error) {
+	return k.clientSet.BatchV1().CronJobs(namespace).List(ctx, apiMetaV1.ListOptions{})
+}
+
+func (k *KubernetesClient) GetCronJob(ctx context.Context, namespace, name string) (*batchV1.CronJob, error) {
+	return k.clientSet.BatchV1().CronJobs(namespace).Get(ctx, name, apiMetaV1.GetOptions{})
+}
+
+func (k *KubernetesClient) PatchCronJob(ctx context.Context, namespace, name string, data []byte) error {
+	_, err := k.clientSet.BatchV1().CronJobs(namespace).Patch(ctx, name, types.StrategicMergePatchType, data, apiMetaV1.PatchOptions{DryRun: k.getDryRun()})
+	return err
+}
+
+func (k *KubernetesClient) GetPreferredGroupVersionResource(group, resource string) (schema.GroupVersionResource, error) {
+	groups, err := k.clientSet.Discovery().ServerGroups()
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+
+	for _, item := range groups.Groups {
+		if item.Name == group {
+			return schema.GroupVersionResource{
+				Group:    group,
+				Version:  item.PreferredVersion.Version,
+				Resource: resource,
+			}, nil
+		}
+	}
+
+	return schema.GroupVersionResource{}, fmt.Errorf("%w: %s", ErrAPIGroupNotFound, group)
+}
+
+func (k *KubernetesClient) GetUnstructured(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	return k.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, apiMetaV1.GetOptions{})
+}
+
+func (k *KubernetesClient) ListUnstructured(ctx context.Context, gvr schema.GroupVersionResource, namespace string) (*unstructured.UnstructuredList, error) {
+	return k.dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, apiMetaV1.ListOptions{})
+}
+
+func (k *KubernetesClient) WatchUnstructured(ctx context.Context, gvr schema.GroupVersionResource, namespace string, listOptions apiMetaV1.ListOptions) (watch.Interface, error) {
+	return k.dynamicClient.Resource(gvr).Namespace(namespace).Watch(ctx, listOptions)
+}
+
+func (k *KubernetesClient) UpdateUnstructured(ctx context.Context, gvr schema.GroupVersionResource, namespace string, object *unstructured.Unstructured, dryRun bool) (*unstructured.Unstructured, error) {
+	updateOptions := apiMetaV1.UpdateOptions{FieldManager: BunnyshellRemoteDevFieldManager, DryRun: k.getDryRun()}
+	if dryRun {
+		updateOptions.DryRun = []string{apiMetaV1.DryRunAll}
+	}
+
+	return k.dynamicClient.Resource(gvr).Namespace(namespace).Update(ctx, object, updateOptions)
+}
+
+func (k *KubernetesClient) BatchPatchUnstructured(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string, data []byte) error {
+	_, err := k.dynamicClient.Resource(gvr).Namespace(namespace).Patch(ctx, name, types.JSONPatchType, data, apiMetaV1.PatchOptions{DryRun: k.getDryRun()})
+	return err
+}
+
+func (k *KubernetesClient) MergePatchUnstructured(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string, data []byte) error {
+	_, err := k.dynamicClient.Resource(gvr).Namespace(namespace).Patch(ctx, name, types.MergePatchType, data, apiMetaV1.PatchOptions{DryRun: k.getDryRun()})
+	return err
+}
+
+func (k *KubernetesClient) ListHorizontalPodAutoscalers(ctx context.Context, namespace string) (*autoscalingV2.HorizontalPodAutoscalerList, error) {
+	return k.clientSet.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, apiMetaV1.ListOptions{})
+}
+
+func (k *KubernetesClient) PatchHorizontalPodAutoscaler(ctx context.Context, namespace, name string, data []byte) error {
+	_, err := k.clientSet.AutoscalingV2().HorizontalPodAutoscalers(namespace).Patch(ctx, name, types.MergePatchType, data, apiMetaV1.PatchOptions{DryRun: k.getDryRun()})
+	return err
+}
+
+func (k *KubernetesClient) GetPod(ctx context.Context, namespace, name string) (*coreV1.Pod, error) {
+	return k.clientSet.CoreV1().Pods(namespace).Get(ctx, name, apiMetaV1.GetOptions{})
+}
+
+func (k *KubernetesClient) DeletePod(ctx context.Context, namespace, name string) error {
+	return k.clientSet.CoreV1().Pods(namespace).Delete(ctx, name, apiMetaV1.DeleteOptions{DryRun: k.getDryRun()})
+}
+
+func (k *KubernetesClient) GetPodLogs(ctx context.Context, namespace, name string, logOptions *coreV1.PodLogOptions) ([]byte, error) {
+	return k.clientSet.CoreV1().Pods(namespace).GetLogs(name, logOptions).DoRaw(ctx)
+}
+
+func (k *KubernetesClient) UpdatePodEphemeralContainers(ctx context.Context, namespace string, pod *coreV1.Pod) (*coreV1.Pod, error) {
+	return k.clientSet.CoreV1().Pods(namespace).UpdateEphemeralContainers(ctx, pod.Name, pod, apiMetaV1.UpdateOptions{DryRun: k.getDryRun()})
+}
+
+func (k *KubernetesClient) Attach(ctx context.Context, pod *coreV1.Pod, containerName string, streamOptions remotecommand.StreamOptions) error {
+	url := k.clientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("attach").
+		VersionedParams(&coreV1.PodAttachOptions{
+			Container: containerName,
+			Stdin:     streamOptions.Stdin != nil,
+			Stdout:    streamOptions.Stdout != nil,
+			Stderr:    streamOptions.Stderr != nil,
+			TTY:       streamOptions.Tty,
+		}, scheme.ParameterCodec).URL()
+
+	return k.stream(ctx, url, streamOptions)
+}
+
+func (k *KubernetesClient) Exec(ctx context.Context, pod *coreV1.Pod, containerName string, command []string, streamOptions remotecommand.StreamOptions) error {
+	url := k.clientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&coreV1.PodExecOptions{
+			Container: containerName,
+			Command:   command,
+			Stdin:     streamOptions.Stdin != nil,
+			Stdout:    streamOptions.Stdout != nil,
+			Stderr:    streamOptions.Stderr != nil,
+			TTY:       streamOptions.Tty,
+		}, scheme.ParameterCodec).URL()
+
+	return k.stream(ctx, url, streamOptions)
+}
+
+// stream prefers the WebSocket protocol and falls back to SPDY for API servers not supporting it
+func (k *KubernetesClient) stream(ctx context.Context, url *url.URL, streamOptions remotecommand.StreamOptions) error {
+	websocketExecutor, err := remotecommand.NewWebSocketExecutor(k.restConfig, RemoteCommandWebSocketMethod, url.String())
+	if err != nil {
+		return err
+	}
+
+	spdyExecutor, err := remotecommand.NewSPDYExecutor(k.restConfig, RemoteCommandMethod, url)
+	if err != nil {
+		return err
+	}
+
+	executor, err := remotecommand.NewFallbackExecutor(websocketExecutor, spdyExecutor, httpstream.IsUpgradeFailure)
+	if err != nil {
+		return err
+	}
+
+	return executor.StreamWithContext(ctx, streamOptions)
 }
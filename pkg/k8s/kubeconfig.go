@@ -4,8 +4,72 @@ import (
 	"os"
 
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdApi "k8s.io/client-go/tools/clientcmd/api"
 )
 
+// KubeConfigOptions select the kubeconfig, the context and the identity of the Kubernetes client
+type KubeConfigOptions struct {
+	// KubeConfigPath replaces the KUBECONFIG files, which are merged like kubectl does
+	KubeConfigPath string
+	// Context replaces the current context
+	Context string
+	// Namespace replaces the namespace of the context
+	Namespace string
+
+	// As and AsGroups impersonate a user and its groups
+	As       string
+	AsGroups []string
+}
+
+var kubeConfigOptions = &KubeConfigOptions{}
+
+// GetKubeConfigOptions returns the options set by the global command flags
+func GetKubeConfigOptions() *KubeConfigOptions {
+	return kubeConfigOptions
+}
+
+// GetContextNamespace returns the namespace set explicitly on the context, empty instead of the implicit
+// "default" namespace
+func (o *KubeConfigOptions) GetContextNamespace() (string, error) {
+	rawConfig, err := o.GetClientConfig().RawConfig()
+	if err != nil {
+		return "", err
+	}
+
+	contextName := o.Context
+	if contextName == "" {
+		contextName = rawConfig.CurrentContext
+	}
+
+	context, ok := rawConfig.Contexts[contextName]
+	if !ok {
+		return "", nil
+	}
+
+	return context.Namespace, nil
+}
+
+func (o *KubeConfigOptions) GetClientConfig() clientcmd.ClientConfig {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = o.KubeConfigPath
+
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: o.Context,
+		Context: clientcmdApi.Context{
+			Namespace: o.Namespace,
+		},
+		AuthInfo: clientcmdApi.AuthInfo{
+			Impersonate:       o.As,
+			ImpersonateGroups: o.AsGroups,
+		},
+	}
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+}
+
+// GetKubeConfigFilePath returns the KUBECONFIG path or the default one
+//
+// Deprecated: use GetKubeConfigOptions, which merges the KUBECONFIG files like kubectl does
 func GetKubeConfigFilePath() string {
 	kubeConfigPath := os.Getenv(clientcmd.RecommendedConfigPathEnvVar)
 	if kubeConfigPath != "" {
@@ -0,0 +1,54 @@
+package patch
+
+import (
+	"encoding/json"
+
+	applyCoreV1 "k8s.io/client-go/applyconfigurations/core/v1"
+	applyMetaV1 "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+// CustomResourcePatchConfiguration places the pod template and replicas at the paths configured for a custom workload kind
+type CustomResourcePatchConfiguration struct {
+	*applyMetaV1.ObjectMetaApplyConfiguration
+
+	Replicas *int32
+	Template *applyCoreV1.PodTemplateSpecApplyConfiguration
+
+	PodTemplatePath []string
+	ReplicasPath    []string
+}
+
+func (c *CustomResourcePatchConfiguration) WithSpecTemplate(value *applyCoreV1.PodTemplateSpecApplyConfiguration) {
+	c.Template = value
+}
+
+func (c *CustomResourcePatchConfiguration) MarshalJSON() ([]byte, error) {
+	object := map[string]any{}
+	if c.ObjectMetaApplyConfiguration != nil {
+		object["metadata"] = c.ObjectMetaApplyConfiguration
+	}
+
+	if c.Template != nil && len(c.PodTemplatePath) > 0 {
+		setField(object, c.PodTemplatePath, c.Template)
+	}
+
+	if c.Replicas != nil && len(c.ReplicasPath) > 0 {
+		setField(object, c.ReplicasPath, c.Replicas)
+	}
+
+	return json.Marshal(object)
+}
+
+func setField(object map[string]any, fields []string, value any) {
+	for _, field := range fields[:len(fields)-1] {
+		child, ok := object[field].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			object[field] = child
+		}
+
+		object = child
+	}
+
+	object[fields[len(fields)-1]] = value
+}
@@ -8,6 +8,7 @@ import (
 )
 
 type DaemonSetPatchConfiguration struct {
+	applyMetaV1.TypeMetaApplyConfiguration    `json:",inline"`
 	*applyMetaV1.ObjectMetaApplyConfiguration `json:"metadata,omitempty"`
 
 	Spec *DaemonSetSpecPatchConfiguration `json:"spec,omitempty"`
@@ -15,7 +16,7 @@ type DaemonSetPatchConfiguration struct {
 
 type DaemonSetSpecPatchConfiguration struct {
 	Template       *applyCoreV1.PodTemplateSpecApplyConfiguration `json:"template,omitempty"`
-	UpdateStrategy *DaemonSetStrategyPatchConfiguration           `json:"strategy,omitempty"`
+	UpdateStrategy *DaemonSetStrategyPatchConfiguration           `json:"updateStrategy,omitempty"`
 }
 
 type DaemonSetStrategyPatchConfiguration struct {
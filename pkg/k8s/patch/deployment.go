@@ -8,6 +8,7 @@ import (
 )
 
 type DeploymentPatchConfiguration struct {
+	applyMetaV1.TypeMetaApplyConfiguration    `json:",inline"`
 	*applyMetaV1.ObjectMetaApplyConfiguration `json:"metadata,omitempty"`
 
 	Spec *DeploymentSpecPatchConfiguration `json:"spec,omitempty"`
@@ -8,6 +8,7 @@ import (
 )
 
 type StatefulSetPatchConfiguration struct {
+	applyMetaV1.TypeMetaApplyConfiguration    `json:",inline"`
 	*applyMetaV1.ObjectMetaApplyConfiguration `json:"metadata,omitempty"`
 
 	Spec *StatefulSetSpecPatchConfiguration `json:"spec,omitempty"`
@@ -16,7 +17,7 @@ type StatefulSetPatchConfiguration struct {
 type StatefulSetSpecPatchConfiguration struct {
 	Replicas       *int32                                         `json:"replicas,omitempty"`
 	Template       *applyCoreV1.PodTemplateSpecApplyConfiguration `json:"template,omitempty"`
-	UpdateStrategy *StatefulSetStrategyPatchConfiguration         `json:"strategy,omitempty"`
+	UpdateStrategy *StatefulSetStrategyPatchConfiguration         `json:"updateStrategy,omitempty"`
 }
 
 type StatefulSetStrategyPatchConfiguration struct {
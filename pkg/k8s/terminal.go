@@ -0,0 +1,66 @@
+package k8s
+
+import (
+	"golang.org/x/term"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// TerminalSizeQueue reports the local terminal size changes to remote command streams
+type TerminalSizeQueue struct {
+	fd int
+
+	resizeChannel chan remotecommand.TerminalSize
+	stopChannel   chan struct{}
+}
+
+func NewTerminalSizeQueue(fd int) *TerminalSizeQueue {
+	queue := &TerminalSizeQueue{
+		fd: fd,
+
+		resizeChannel: make(chan remotecommand.TerminalSize, 1),
+		stopChannel:   make(chan struct{}),
+	}
+
+	// initial size
+	queue.push()
+
+	go queue.monitor()
+
+	return queue
+}
+
+func (t *TerminalSizeQueue) Next() *remotecommand.TerminalSize {
+	select {
+	case size := <-t.resizeChannel:
+		return &size
+	case <-t.stopChannel:
+		return nil
+	}
+}
+
+func (t *TerminalSizeQueue) Stop() {
+	close(t.stopChannel)
+}
+
+func (t *TerminalSizeQueue) push() {
+	width, height, err := term.GetSize(t.fd)
+	if err != nil {
+		return
+	}
+
+	size := remotecommand.TerminalSize{
+		Width:  uint16(width),
+		Height: uint16(height),
+	}
+
+	// drop the pending size, only the latest one matters
+	select {
+	case <-t.resizeChannel:
+	default:
+	}
+
+	select {
+	case t.resizeChannel <- size:
+	default:
+	}
+}
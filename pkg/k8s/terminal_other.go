@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package k8s
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func (t *TerminalSizeQueue) monitor() {
+	resizeSignal := make(chan os.Signal, 1)
+	signal.Notify(resizeSignal, syscall.SIGWINCH)
+	defer signal.Stop(resizeSignal)
+
+	for {
+		select {
+		case <-resizeSignal:
+			t.push()
+		case <-t.stopChannel:
+			return
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package k8s
+
+import (
+	"time"
+
+	"golang.org/x/term"
+)
+
+const terminalResizePollInterval = 250 * time.Millisecond
+
+// windows has no SIGWINCH, poll the console size instead
+func (t *TerminalSizeQueue) monitor() {
+	lastWidth, lastHeight, _ := term.GetSize(t.fd)
+
+	ticker := time.NewTicker(terminalResizePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			width, height, err := term.GetSize(t.fd)
+			if err != nil || (width == lastWidth && height == lastHeight) {
+				continue
+			}
+
+			lastWidth, lastHeight = width, height
+			t.push()
+		case <-t.stopChannel:
+			return
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"strings"
+)
+
+const (
+	DockerHubDomain = "docker.io"
+
+	dockerHubLibrary = "library/"
+)
+
+// SplitImageDomain returns the registry domain and the repository path of the image, including the
+// implicit Docker Hub ones, e.g. busybox:1.36 -> docker.io, library/busybox:1.36
+func SplitImageDomain(image string) (string, string) {
+	domain, path, ok := strings.Cut(image, "/")
+	if !ok {
+		return DockerHubDomain, dockerHubLibrary + image
+	}
+
+	if !strings.ContainsAny(domain, ".:") && domain != "localhost" {
+		// e.g. bitnami/kubectl on Docker Hub
+		return DockerHubDomain, image
+	}
+
+	return domain, path
+}
+
+// GetMirrorImage pulls the image from the registry mirror, keeping its repository path
+func GetMirrorImage(image, registry string) string {
+	registry = strings.TrimRight(registry, "/")
+	if registry == "" {
+		return image
+	}
+
+	_, path := SplitImageDomain(image)
+
+	return registry + "/" + path
+}
@@ -0,0 +1,22 @@
+package tools
+
+import (
+	"testing"
+)
+
+func TestGetMirrorImage(t *testing.T) {
+	for image, expected := range map[string]string{
+		"busybox:1.36":         "mirror.local/library/busybox:1.36",
+		"bitnami/kubectl:1.29": "mirror.local/bitnami/kubectl:1.29",
+		"public.ecr.aws/x0p9x6p7/bunnyshell/remote-binaries": "mirror.local/x0p9x6p7/bunnyshell/remote-binaries",
+		"localhost:5000/busybox":                             "mirror.local/busybox",
+	} {
+		if actual := GetMirrorImage(image, "mirror.local/"); actual != expected {
+			t.Errorf("%s: expected %s, got %s", image, expected, actual)
+		}
+	}
+
+	if actual := GetMirrorImage("busybox:1.36", ""); actual != "busybox:1.36" {
+		t.Errorf("expected the image unchanged, got %s", actual)
+	}
+}
@@ -0,0 +1,210 @@
+package tools
+
+import (
+	"fmt"
+
+	coreV1 "k8s.io/api/core/v1"
+	applyCoreV1 "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+const (
+	PodSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+	PodSecurityRestricted   = "restricted"
+	PodSecurityBaseline     = "baseline"
+
+	// DefaultRunAsUser runs the injected containers of restricted namespaces when the target sets no user
+	DefaultRunAsUser int64 = 1000
+)
+
+func IsRestrictedNamespace(namespace *coreV1.Namespace) bool {
+	return namespace != nil && namespace.GetLabels()[PodSecurityEnforceLabel] == PodSecurityRestricted
+}
+
+// GetRunAsIdentity returns the non-root user and the group of the container, inherited from the pod
+// when the container sets none; nil when the image decides
+func GetRunAsIdentity(podSpec *coreV1.PodSpec, container *coreV1.Container) (*int64, *int64) {
+	var runAsUser, runAsGroup *int64
+	if podSecurityContext := podSpec.SecurityContext; podSecurityContext != nil {
+		runAsUser, runAsGroup = podSecurityContext.RunAsUser, podSecurityContext.RunAsGroup
+	}
+
+	if securityContext := container.SecurityContext; securityContext != nil {
+		if securityContext.RunAsUser != nil {
+			runAsUser = securityContext.RunAsUser
+		}
+		if securityContext.RunAsGroup != nil {
+			runAsGroup = securityContext.RunAsGroup
+		}
+	}
+
+	if runAsUser != nil && *runAsUser == 0 {
+		runAsUser = nil
+	}
+
+	return runAsUser, runAsGroup
+}
+
+// GetInjectedSecurityContext runs a container injected next to the target one as the target user, satisfying
+// the restricted Pod Security Standard; without a known user, only restricted namespaces get the default
+// non-root user, elsewhere the image user is kept as it might need root, e.g. to read the synced folder
+func GetInjectedSecurityContext(runAsUser, runAsGroup *int64, restricted bool) *applyCoreV1.SecurityContextApplyConfiguration {
+	securityContext := applyCoreV1.SecurityContext().
+		WithAllowPrivilegeEscalation(false).
+		WithSeccompProfile(applyCoreV1.SeccompProfile().WithType(coreV1.SeccompProfileTypeRuntimeDefault))
+
+	if runAsUser == nil {
+		if !restricted {
+			return securityContext
+		}
+
+		defaultRunAsUser := DefaultRunAsUser
+		runAsUser = &defaultRunAsUser
+	}
+
+	securityContext.
+		WithRunAsUser(*runAsUser).
+		WithRunAsNonRoot(true).
+		WithCapabilities(applyCoreV1.Capabilities().WithDrop("ALL"))
+	if runAsGroup != nil {
+		securityContext.WithRunAsGroup(*runAsGroup)
+	}
+
+	return securityContext
+}
+
+// baselineCapabilities may be added to the containers of baseline namespaces
+var baselineCapabilities = map[coreV1.Capability]bool{
+	"AUDIT_WRITE":      true,
+	"CHOWN":            true,
+	"DAC_OVERRIDE":     true,
+	"FOWNER":           true,
+	"FSETID":           true,
+	"KILL":             true,
+	"MKNOD":            true,
+	"NET_BIND_SERVICE": true,
+	"SETFCAP":          true,
+	"SETGID":           true,
+	"SETPCAP":          true,
+	"SETUID":           true,
+	"SYS_CHROOT":       true,
+}
+
+// GetPodSecurityViolations returns the main checks of the baseline or restricted Pod Security Standard failed by the pod,
+// nil for any other level
+func GetPodSecurityViolations(level string, podSpec *coreV1.PodSpec) []string {
+	if level != PodSecurityBaseline && level != PodSecurityRestricted {
+		return nil
+	}
+
+	restricted := level == PodSecurityRestricted
+	violations := []string{}
+
+	if podSpec.HostNetwork || podSpec.HostPID || podSpec.HostIPC {
+		violations = append(violations, "host namespaces")
+	}
+
+	for _, volume := range podSpec.Volumes {
+		if volume.HostPath != nil {
+			violations = append(violations, fmt.Sprintf("hostPath volume \"%s\"", volume.Name))
+		} else if restricted && !isRestrictedVolume(volume) {
+			violations = append(violations, fmt.Sprintf("volume \"%s\" of a restricted type", volume.Name))
+		}
+	}
+
+	podSecurityContext := podSpec.SecurityContext
+	if podSecurityContext == nil {
+		podSecurityContext = &coreV1.PodSecurityContext{}
+	}
+
+	containers := append(append([]coreV1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+	for _, container := range containers {
+		for _, violation := range getContainerSecurityViolations(restricted, podSecurityContext, &container) {
+			violations = append(violations, fmt.Sprintf("container \"%s\": %s", container.Name, violation))
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return violations
+}
+
+func getContainerSecurityViolations(restricted bool, podSecurityContext *coreV1.PodSecurityContext, container *coreV1.Container) []string {
+	violations := []string{}
+
+	for _, port := range container.Ports {
+		if port.HostPort != 0 {
+			violations = append(violations, fmt.Sprintf("hostPort %d", port.HostPort))
+		}
+	}
+
+	securityContext := container.SecurityContext
+	if securityContext == nil {
+		securityContext = &coreV1.SecurityContext{}
+	}
+
+	if securityContext.Privileged != nil && *securityContext.Privileged {
+		violations = append(violations, "privileged")
+	}
+
+	seccompProfile := securityContext.SeccompProfile
+	if seccompProfile == nil {
+		seccompProfile = podSecurityContext.SeccompProfile
+	}
+	if seccompProfile != nil && seccompProfile.Type == coreV1.SeccompProfileTypeUnconfined {
+		violations = append(violations, "unconfined seccomp profile")
+	} else if restricted && seccompProfile == nil {
+		violations = append(violations, "seccompProfile not set to RuntimeDefault or Localhost")
+	}
+
+	dropsAll := false
+	if capabilities := securityContext.Capabilities; capabilities != nil {
+		for _, capability := range capabilities.Add {
+			if !baselineCapabilities[capability] || (restricted && capability != "NET_BIND_SERVICE") {
+				violations = append(violations, fmt.Sprintf("capability %s added", capability))
+			}
+		}
+
+		for _, capability := range capabilities.Drop {
+			dropsAll = dropsAll || capability == "ALL"
+		}
+	}
+
+	if !restricted {
+		return violations
+	}
+
+	if !dropsAll {
+		violations = append(violations, "capabilities not dropping ALL")
+	}
+
+	if securityContext.AllowPrivilegeEscalation == nil || *securityContext.AllowPrivilegeEscalation {
+		violations = append(violations, "allowPrivilegeEscalation not set to false")
+	}
+
+	runAsNonRoot := securityContext.RunAsNonRoot
+	if runAsNonRoot == nil {
+		runAsNonRoot = podSecurityContext.RunAsNonRoot
+	}
+	if runAsNonRoot == nil || !*runAsNonRoot {
+		violations = append(violations, "runAsNonRoot not set to true")
+	}
+
+	runAsUser := securityContext.RunAsUser
+	if runAsUser == nil {
+		runAsUser = podSecurityContext.RunAsUser
+	}
+	if runAsUser != nil && *runAsUser == 0 {
+		violations = append(violations, "runAsUser 0")
+	}
+
+	return violations
+}
+
+func isRestrictedVolume(volume coreV1.Volume) bool {
+	source := volume.VolumeSource
+
+	return source.ConfigMap != nil || source.CSI != nil || source.DownwardAPI != nil || source.EmptyDir != nil ||
+		source.Ephemeral != nil || source.PersistentVolumeClaim != nil || source.Projected != nil || source.Secret != nil
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+
+	coreV1 "k8s.io/api/core/v1"
+	applyCoreV1 "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+func TestGetInjectedSecurityContext(t *testing.T) {
+	var rootUser, podUser, containerUser, podGroup int64 = 0, 1001, 1002, 2001
+
+	// the container user overrides the pod user, root is left to the image
+	podSpec := &coreV1.PodSpec{SecurityContext: &coreV1.PodSecurityContext{RunAsUser: &podUser, RunAsGroup: &podGroup}}
+	container := &coreV1.Container{SecurityContext: &coreV1.SecurityContext{RunAsUser: &containerUser}}
+	if runAsUser, runAsGroup := GetRunAsIdentity(podSpec, container); *runAsUser != containerUser || *runAsGroup != podGroup {
+		t.Errorf("expected %d:%d, got %d:%d", containerUser, podGroup, *runAsUser, *runAsGroup)
+	}
+
+	container.SecurityContext.RunAsUser = &rootUser
+	if runAsUser, _ := GetRunAsIdentity(podSpec, container); runAsUser != nil {
+		t.Errorf("expected no user, got %d", *runAsUser)
+	}
+
+	baseline := applyCoreV1.SecurityContext().
+		WithAllowPrivilegeEscalation(false).
+		WithSeccompProfile(applyCoreV1.SeccompProfile().WithType(coreV1.SeccompProfileTypeRuntimeDefault))
+	if actual := GetInjectedSecurityContext(nil, &podGroup, false); !reflect.DeepEqual(baseline, actual) {
+		t.Errorf("expected %+v, got %+v", baseline, actual)
+	}
+
+	if actual := GetInjectedSecurityContext(nil, nil, true); *actual.RunAsUser != DefaultRunAsUser || !*actual.RunAsNonRoot || actual.Capabilities.Drop[0] != "ALL" {
+		t.Errorf("expected the restricted default user, got %+v", actual)
+	}
+
+	if actual := GetInjectedSecurityContext(&podUser, &podGroup, false); *actual.RunAsUser != podUser || *actual.RunAsGroup != podGroup {
+		t.Errorf("expected %d:%d, got %+v", podUser, podGroup, actual)
+	}
+}
+
+func TestGetPodSecurityViolations(t *testing.T) {
+	allowPrivilegeEscalation, runAsNonRoot := false, true
+	podSpec := &coreV1.PodSpec{
+		SecurityContext: &coreV1.PodSecurityContext{
+			RunAsNonRoot:   &runAsNonRoot,
+			SeccompProfile: &coreV1.SeccompProfile{Type: coreV1.SeccompProfileTypeRuntimeDefault},
+		},
+		Containers: []coreV1.Container{{
+			Name: "api",
+			SecurityContext: &coreV1.SecurityContext{
+				AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+				Capabilities:             &coreV1.Capabilities{Drop: []coreV1.Capability{"ALL"}},
+			},
+		}},
+	}
+	if violations := GetPodSecurityViolations(PodSecurityRestricted, podSpec); violations != nil {
+		t.Errorf("expected no violations, got %q", violations)
+	}
+
+	podSpec.HostNetwork = true
+	podSpec.Containers[0].SecurityContext = nil
+	expected := []string{"host namespaces"}
+	if violations := GetPodSecurityViolations(PodSecurityBaseline, podSpec); !reflect.DeepEqual(expected, violations) {
+		t.Errorf("expected %q, got %q", expected, violations)
+	}
+
+	expected = append(expected, `container "api": capabilities not dropping ALL`, `container "api": allowPrivilegeEscalation not set to false`)
+	if violations := GetPodSecurityViolations(PodSecurityRestricted, podSpec); !reflect.DeepEqual(expected, violations) {
+		t.Errorf("expected %q, got %q", expected, violations)
+	}
+}
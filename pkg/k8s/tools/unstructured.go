@@ -0,0 +1,183 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	coreV1 "k8s.io/api/core/v1"
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// SplitFieldPath turns a dot separated path, e.g. "spec.template", into its fields
+func SplitFieldPath(path string) []string {
+	if path == "" {
+		return []string{}
+	}
+
+	return strings.Split(path, ".")
+}
+
+// GetJSONPointer turns a dot separated path into a JSON patch path
+func GetJSONPointer(path string) string {
+	pointer := ""
+	for _, field := range SplitFieldPath(path) {
+		field = strings.ReplaceAll(field, "~", "~0")
+		pointer += "/" + strings.ReplaceAll(field, "/", "~1")
+	}
+
+	return pointer
+}
+
+func GetUnstructuredPodTemplate(resource *unstructured.Unstructured, podTemplatePath string) (*coreV1.PodTemplateSpec, error) {
+	object, found, err := unstructured.NestedMap(resource.Object, SplitFieldPath(podTemplatePath)...)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("pod template \"%s\" not found in %s \"%s\"", podTemplatePath, resource.GetKind(), resource.GetName())
+	}
+
+	podTemplate := &coreV1.PodTemplateSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object, podTemplate); err != nil {
+		return nil, err
+	}
+
+	return podTemplate, nil
+}
+
+func GetUnstructuredContainers(resource *unstructured.Unstructured, podTemplatePath string) ([]coreV1.Container, error) {
+	podTemplate, err := GetUnstructuredPodTemplate(resource, podTemplatePath)
+	if err != nil {
+		return []coreV1.Container{}, err
+	}
+
+	return podTemplate.Spec.Containers, nil
+}
+
+func GetUnstructuredContainerByName(resource *unstructured.Unstructured, podTemplatePath, containerName string) (*coreV1.Container, error) {
+	containers, err := GetUnstructuredContainers(resource, podTemplatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return FilterContainerByName(containers, containerName)
+}
+
+func GetUnstructuredInitContainers(resource *unstructured.Unstructured, podTemplatePath string) ([]coreV1.Container, error) {
+	podTemplate, err := GetUnstructuredPodTemplate(resource, podTemplatePath)
+	if err != nil {
+		return []coreV1.Container{}, err
+	}
+
+	return podTemplate.Spec.InitContainers, nil
+}
+
+func GetUnstructuredInitContainerByName(resource *unstructured.Unstructured, podTemplatePath, initContainerName string) (*coreV1.Container, error) {
+	containers, err := GetUnstructuredInitContainers(resource, podTemplatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return FilterContainerByName(containers, initContainerName)
+}
+
+// GetUnstructuredSelector reads either a LabelSelector or a plain label map from selectorPath,
+// or selects the pods labeled with the resource name for kinds without a selector
+func GetUnstructuredSelector(resource *unstructured.Unstructured, selectorPath, selectorLabel string) (*apiMetaV1.LabelSelector, error) {
+	if selectorPath == "" {
+		if selectorLabel == "" {
+			return nil, fmt.Errorf("no selector configured for %s", resource.GetKind())
+		}
+
+		return &apiMetaV1.LabelSelector{
+			MatchLabels: map[string]string{selectorLabel: resource.GetName()},
+		}, nil
+	}
+
+	object, found, err := unstructured.NestedMap(resource.Object, SplitFieldPath(selectorPath)...)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("selector \"%s\" not found in %s \"%s\"", selectorPath, resource.GetKind(), resource.GetName())
+	}
+
+	_, hasMatchLabels := object["matchLabels"]
+	_, hasMatchExpressions := object["matchExpressions"]
+	if hasMatchLabels || hasMatchExpressions {
+		selector := &apiMetaV1.LabelSelector{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object, selector); err != nil {
+			return nil, err
+		}
+
+		return selector, nil
+	}
+
+	matchLabels, _, err := unstructured.NestedStringMap(resource.Object, SplitFieldPath(selectorPath)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiMetaV1.LabelSelector{MatchLabels: matchLabels}, nil
+}
+
+// ApplyUnstructuredPatch merges the pod template found at podTemplatePath using the pod template
+// strategy, as custom resources only support JSON merge patches which would replace the containers,
+// and the rest of the patch as a JSON merge patch
+func ApplyUnstructuredPatch(resource *unstructured.Unstructured, patch map[string]any, podTemplatePath string) error {
+	podTemplateFields := SplitFieldPath(podTemplatePath)
+
+	podTemplatePatch, found, err := unstructured.NestedMap(patch, podTemplateFields...)
+	if err != nil {
+		return err
+	}
+
+	if found {
+		unstructured.RemoveNestedField(patch, podTemplateFields...)
+
+		podTemplate, _, err := unstructured.NestedMap(resource.Object, podTemplateFields...)
+		if err != nil {
+			return err
+		}
+
+		podTemplate, err = strategicpatch.StrategicMergeMapPatch(podTemplate, podTemplatePatch, coreV1.PodTemplateSpec{})
+		if err != nil {
+			return err
+		}
+
+		if err := unstructured.SetNestedMap(resource.Object, podTemplate, podTemplateFields...); err != nil {
+			return err
+		}
+	}
+
+	mergeObject(resource.Object, patch)
+
+	return nil
+}
+
+func mergeObject(object map[string]any, patch map[string]any) {
+	for key, value := range patch {
+		if value == nil {
+			delete(object, key)
+			continue
+		}
+
+		patchValue, isPatchObject := value.(map[string]any)
+		currentValue, isCurrentObject := object[key].(map[string]any)
+		if isPatchObject && isCurrentObject {
+			mergeObject(currentValue, patchValue)
+			continue
+		}
+
+		if isPatchObject {
+			currentValue = map[string]any{}
+			mergeObject(currentValue, patchValue)
+			value = currentValue
+		}
+
+		object[key] = value
+	}
+}
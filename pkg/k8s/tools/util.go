@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	appsV1 "k8s.io/api/apps/v1"
+	batchV1 "k8s.io/api/batch/v1"
 	coreV1 "k8s.io/api/core/v1"
 )
 
@@ -69,4 +70,22 @@ func GetDaemonSetInitContainers(daemonSet *appsV1.DaemonSet) []coreV1.Container
 func GetDaemonSetInitContainerByName(daemonSet *appsV1.DaemonSet, initContainerName string) (*coreV1.Container, error) {
 	containers := GetDaemonSetInitContainers(daemonSet)
 	return FilterContainerByName(containers, initContainerName)
+}
+
+func GetJobContainers(job *batchV1.Job) []coreV1.Container {
+	return job.Spec.Template.Spec.Containers
+}
+
+func GetJobContainerByName(job *batchV1.Job, containerName string) (*coreV1.Container, error) {
+	containers := GetJobContainers(job)
+	return FilterContainerByName(containers, containerName)
+}
+
+func GetCronJobContainers(cronJob *batchV1.CronJob) []coreV1.Container {
+	return cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers
+}
+
+func GetCronJobContainerByName(cronJob *batchV1.CronJob, containerName string) (*coreV1.Container, error) {
+	containers := GetCronJobContainers(cronJob)
+	return FilterContainerByName(containers, containerName)
 }
\ No newline at end of file
@@ -0,0 +1,291 @@
+package workload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"bunnyshell.com/dev/pkg/k8s"
+
+	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// ApplyOptions of a server-side apply with the bunnyshell-dev field manager
+type ApplyOptions struct {
+	// Force takes over the fields owned by other field managers
+	Force bool
+	// DryRun only validates the apply, so conflicts are found without changing the workload
+	DryRun bool
+}
+
+// FieldConflict is a field owned by another field manager, e.g. kubectl or helm
+type FieldConflict struct {
+	Field   string
+	Message string
+}
+
+// GetFieldConflicts returns the field conflicts of a failed apply, or nil for any other error
+func GetFieldConflicts(err error) []FieldConflict {
+	var status apiErrors.APIStatus
+	if !apiErrors.IsConflict(err) || !errors.As(err, &status) || status.Status().Details == nil {
+		return nil
+	}
+
+	conflicts := []FieldConflict{}
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type != apiMetaV1.CauseTypeFieldManagerConflict {
+			continue
+		}
+
+		conflicts = append(conflicts, FieldConflict{Field: cause.Field, Message: cause.Message})
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	return conflicts
+}
+
+// takeOver writes the take over patch of the workload, when there is anything to take over
+func takeOver(ctx context.Context, w Workload, containerName string, fields []string, data []byte) error {
+	takeOverPatch, err := w.GetTakeOverPatch(ctx, containerName, fields, data)
+	if err != nil || takeOverPatch == nil {
+		return err
+	}
+
+	return w.BatchPatch(ctx, takeOverPatch)
+}
+
+// restore writes the release patch of the workload, then relinquishes the fields of the bunnyshell-dev field manager
+func restore(ctx context.Context, w Workload, manifest string) error {
+	data, err := w.GetRestorePatch(ctx, manifest)
+	if err != nil {
+		return err
+	}
+
+	if err := w.Patch(ctx, data); err != nil {
+		return err
+	}
+
+	return releaseFields(ctx, w)
+}
+
+// releaseFields applies an empty configuration with the bunnyshell-dev field manager, so it owns no fields anymore
+func releaseFields(ctx context.Context, w Workload) error {
+	live, err := w.Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	hasOwnApplyEntry := false
+	for _, entry := range live.GetManagedFields() {
+		hasOwnApplyEntry = hasOwnApplyEntry || isOwnApplyEntry(entry)
+	}
+	if !hasOwnApplyEntry {
+		return nil
+	}
+
+	data, err := GetReleaseConfiguration(w)
+	if err != nil {
+		return err
+	}
+
+	return w.Apply(ctx, data, ApplyOptions{})
+}
+
+// GetReleaseConfiguration returns the empty configuration applied by Restore
+func GetReleaseConfiguration(w Workload) ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"apiVersion": w.GetAPIVersion(),
+		"kind":       w.GetKind(),
+		"metadata": map[string]any{
+			"name":      w.GetName(),
+			"namespace": w.GetNamespace(),
+		},
+	})
+}
+
+// getTakeOverPatch returns the JSON patch removing the container fields, which an apply cannot drop while other
+// field managers own them, and replacing the atomic resource fields, e.g. the update strategy, with their applied
+// value; the patch tests the resource version and the container, so it fails if the workload changed meanwhile
+func getTakeOverPatch(ctx context.Context, w Workload, containerName string, fields []string, data []byte, resourceFields ...[]string) ([]byte, error) {
+	live, err := w.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	containerPath, liveContainer, err := getContainerPath(live, containerName)
+	if err != nil {
+		return nil, err
+	}
+
+	container, err := toObject(liveContainer)
+	if err != nil {
+		return nil, err
+	}
+
+	containerPath = w.GetPodTemplatePath() + containerPath
+	operations := []map[string]any{}
+	for _, field := range fields {
+		if _, ok := container[field]; ok {
+			operations = append(operations, map[string]any{"op": "remove", "path": containerPath + "/" + field})
+		}
+	}
+
+	resourceOperations, err := getResourceFieldOperations(live, data, resourceFields)
+	if err != nil {
+		return nil, err
+	}
+	operations = append(operations, resourceOperations...)
+
+	if len(operations) == 0 {
+		return nil, nil
+	}
+
+	tests := []map[string]any{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": live.GetResourceVersion()},
+		{"op": "test", "path": containerPath + "/name", "value": containerName},
+	}
+
+	return json.Marshal(append(tests, operations...))
+}
+
+// getContainerPath returns the path of the container or init container, relative to the pod template
+func getContainerPath(live Workload, containerName string) (string, *coreV1.Container, error) {
+	containers, err := live.GetContainers()
+	if err != nil {
+		return "", nil, err
+	}
+
+	for i := range containers {
+		if containers[i].Name == containerName {
+			return fmt.Sprintf("/spec/containers/%d", i), &containers[i], nil
+		}
+	}
+
+	initContainers, err := live.GetInitContainers()
+	if err != nil {
+		return "", nil, err
+	}
+
+	for i := range initContainers {
+		if initContainers[i].Name == containerName {
+			return fmt.Sprintf("/spec/initContainers/%d", i), &initContainers[i], nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("%w: %s", ErrContainerNotFound, containerName)
+}
+
+// getResourceFieldOperations returns the operations replacing the resource fields set by the apply data
+func getResourceFieldOperations(live Workload, data []byte, resourceFields [][]string) ([]map[string]any, error) {
+	if len(resourceFields) == 0 {
+		return nil, nil
+	}
+
+	applied := map[string]any{}
+	if err := json.Unmarshal(data, &applied); err != nil {
+		return nil, err
+	}
+
+	object, err := toObject(live.GetObject())
+	if err != nil {
+		return nil, err
+	}
+
+	operations := []map[string]any{}
+	for _, resourceField := range resourceFields {
+		value, found, err := unstructured.NestedFieldNoCopy(applied, resourceField...)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+
+		value = withoutNulls(value)
+		current, found, err := unstructured.NestedFieldNoCopy(object, resourceField...)
+		if err != nil {
+			return nil, err
+		}
+		if found && reflect.DeepEqual(current, value) {
+			continue
+		}
+
+		operation := "replace"
+		if !found {
+			operation = "add"
+		}
+
+		operations = append(operations, map[string]any{
+			"op":    operation,
+			"path":  "/" + strings.Join(resourceField, "/"),
+			"value": value,
+		})
+	}
+
+	return operations, nil
+}
+
+func toObject(value any) (map[string]any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	object := map[string]any{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+// withoutNulls drops the null fields of the apply data, which only mean the field must be absent
+func withoutNulls(value any) any {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+
+	result := map[string]any{}
+	for key, child := range object {
+		if child != nil {
+			result[key] = withoutNulls(child)
+		}
+	}
+
+	return result
+}
+
+func isOwnApplyEntry(entry apiMetaV1.ManagedFieldsEntry) bool {
+	return entry.Manager == k8s.BunnyshellRemoteDevFieldManager && entry.Operation == apiMetaV1.ManagedFieldsOperationApply
+}
+
+// getReleasePatch returns the strategic merge patch reverting the live workload to the manifest
+func getReleasePatch(ctx context.Context, w Workload, manifest string) ([]byte, error) {
+	live, err := w.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	liveSnapshot, err := live.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := w.GetSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	return strategicpatch.CreateTwoWayMergePatch([]byte(liveSnapshot), []byte(manifest), schema)
+}
@@ -0,0 +1,78 @@
+package workload
+
+import (
+	"context"
+	"testing"
+
+	appsV1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// liveWorkload is its own live state, instead of being read from the cluster
+type liveWorkload struct {
+	Workload
+}
+
+func (w *liveWorkload) Get(_ context.Context) (Workload, error) {
+	return w.Workload, nil
+}
+
+func TestGetTakeOverPatch(t *testing.T) {
+	deployment := &appsV1.Deployment{
+		ObjectMeta: apiMetaV1.ObjectMeta{Name: "api", ResourceVersion: "42"},
+		Spec: appsV1.DeploymentSpec{
+			Strategy: appsV1.DeploymentStrategy{Type: appsV1.RollingUpdateDeploymentStrategyType},
+			Template: coreV1.PodTemplateSpec{Spec: coreV1.PodSpec{Containers: []coreV1.Container{
+				{Name: "sidecar"},
+				{Name: "api", Args: []string{"serve"}},
+			}}},
+		},
+	}
+	w := &liveWorkload{Workload: NewDeployment(nil, deployment)}
+	strategyField := []string{"spec", "strategy"}
+	data := []byte(`{"spec":{"strategy":{"type":"Recreate","rollingUpdate":null}}}`)
+
+	tests := []struct {
+		fields   []string
+		expected string
+	}{
+		{
+			fields: []string{"args", "livenessProbe"},
+			expected: `[{"op":"test","path":"/metadata/resourceVersion","value":"42"},` +
+				`{"op":"test","path":"/spec/template/spec/containers/1/name","value":"api"},` +
+				`{"op":"remove","path":"/spec/template/spec/containers/1/args"},` +
+				`{"op":"replace","path":"/spec/strategy","value":{"type":"Recreate"}}]`,
+		},
+		{
+			fields: []string{"livenessProbe"},
+			expected: `[{"op":"test","path":"/metadata/resourceVersion","value":"42"},` +
+				`{"op":"test","path":"/spec/template/spec/containers/1/name","value":"api"},` +
+				`{"op":"replace","path":"/spec/strategy","value":{"type":"Recreate"}}]`,
+		},
+	}
+
+	for _, test := range tests {
+		actual, err := getTakeOverPatch(context.Background(), w, "api", test.fields, data, strategyField)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(actual) != test.expected {
+			t.Errorf("%v: expected %s, got %s", test.fields, test.expected, actual)
+		}
+	}
+
+	deployment.Spec.Strategy.Type = appsV1.RecreateDeploymentStrategyType
+	if actual, err := getTakeOverPatch(context.Background(), w, "api", []string{"livenessProbe"}, data, strategyField); err != nil || actual != nil {
+		t.Errorf("expected no patch, got %s, %v", actual, err)
+	}
+
+	deployment.Spec.Template.Spec.InitContainers = []coreV1.Container{{Name: "migrate", Args: []string{"up"}}}
+	expected := `[{"op":"test","path":"/metadata/resourceVersion","value":"42"},` +
+		`{"op":"test","path":"/spec/template/spec/initContainers/0/name","value":"migrate"},` +
+		`{"op":"remove","path":"/spec/template/spec/initContainers/0/args"}]`
+	if actual, _ := getTakeOverPatch(context.Background(), w, "migrate", []string{"args"}, data, strategyField); string(actual) != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
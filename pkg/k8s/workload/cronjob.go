@@ -0,0 +1,117 @@
+package workload
+
+import (
+	"context"
+
+	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/k8s/patch"
+	k8sTools "bunnyshell.com/dev/pkg/k8s/tools"
+
+	batchV1 "k8s.io/api/batch/v1"
+	coreV1 "k8s.io/api/core/v1"
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// cronJob sessions run on a Job created from the job template, only the metadata and schedule are patched
+type cronJob struct {
+	*batchV1.CronJob
+
+	client *k8s.KubernetesClient
+}
+
+func NewCronJob(client *k8s.KubernetesClient, object *batchV1.CronJob) Workload {
+	return &cronJob{CronJob: object, client: client}
+}
+
+func (w *cronJob) GetResourceType() ResourceType {
+	return CronJob
+}
+
+func (w *cronJob) GetAPIVersion() string {
+	return "batch/v1"
+}
+
+func (w *cronJob) GetKind() string {
+	return "CronJob"
+}
+
+func (w *cronJob) GetObject() Resource {
+	return w.CronJob
+}
+
+func (w *cronJob) Get(ctx context.Context) (Workload, error) {
+	return Get(ctx, w.client, CronJob, w.GetNamespace(), w.GetName())
+}
+
+func (w *cronJob) Watch(ctx context.Context, listOptions apiMetaV1.ListOptions) (watch.Interface, error) {
+	return nil, notSupportedError(w, "watch")
+}
+
+func (w *cronJob) Delete(ctx context.Context) error {
+	return notSupportedError(w, "delete")
+}
+
+func (w *cronJob) Clone(ctx context.Context, objectMeta apiMetaV1.ObjectMeta, cloneFunc CloneFunc) (Workload, error) {
+	return nil, notSupportedError(w, "clone")
+}
+
+func (w *cronJob) GetSnapshot() (string, error) {
+	return getSnapshot(w.CronJob)
+}
+
+func (w *cronJob) GetPatch() (patch.Resource, error) {
+	return nil, notSupportedError(w, "pod template patch")
+}
+
+func (w *cronJob) Patch(ctx context.Context, data []byte) error {
+	return w.client.PatchCronJob(ctx, w.GetNamespace(), w.GetName(), data)
+}
+
+func (w *cronJob) BatchPatch(ctx context.Context, data []byte) error {
+	return notSupportedError(w, "json patch")
+}
+
+func (w *cronJob) Apply(ctx context.Context, data []byte, options ApplyOptions) error {
+	return notSupportedError(w, "apply")
+}
+
+func (w *cronJob) TakeOver(ctx context.Context, containerName string, fields []string, data []byte) error {
+	return notSupportedError(w, "take over")
+}
+
+func (w *cronJob) GetTakeOverPatch(ctx context.Context, containerName string, fields []string, data []byte) ([]byte, error) {
+	return nil, notSupportedError(w, "take over")
+}
+
+func (w *cronJob) Restore(ctx context.Context, manifest string) error {
+	return notSupportedError(w, "restore")
+}
+
+func (w *cronJob) GetRestorePatch(ctx context.Context, manifest string) ([]byte, error) {
+	return nil, notSupportedError(w, "restore")
+}
+
+func (w *cronJob) GetSchema() (any, error) {
+	return batchV1.CronJob{}, nil
+}
+
+func (w *cronJob) GetSelector() (*apiMetaV1.LabelSelector, error) {
+	return nil, notSupportedError(w, "selector")
+}
+
+func (w *cronJob) GetPodTemplatePath() string {
+	return "/spec/jobTemplate/spec/template"
+}
+
+func (w *cronJob) GetContainers() ([]coreV1.Container, error) {
+	return k8sTools.GetCronJobContainers(w.CronJob), nil
+}
+
+func (w *cronJob) GetInitContainers() ([]coreV1.Container, error) {
+	return w.Spec.JobTemplate.Spec.Template.Spec.InitContainers, nil
+}
+
+func (w *cronJob) GetPodSpec() (*coreV1.PodSpec, error) {
+	return &w.Spec.JobTemplate.Spec.Template.Spec, nil
+}
@@ -0,0 +1,274 @@
+package workload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"bunnyshell.com/dev/pkg/config"
+	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/k8s/patch"
+	k8sTools "bunnyshell.com/dev/pkg/k8s/tools"
+
+	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	applyMetaV1 "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+var ErrWorkloadNotConfigured = fmt.Errorf("workload kind not configured")
+
+// customResource is a workload kind mapped in the configuration, read and written as unstructured data
+type customResource struct {
+	*unstructured.Unstructured
+
+	client *k8s.KubernetesClient
+	kind   *config.Workload
+}
+
+func NewCustomResource(client *k8s.KubernetesClient, object *unstructured.Unstructured, kind *config.Workload) Workload {
+	return &customResource{Unstructured: object, client: client, kind: kind}
+}
+
+// GetCustomResource reads a custom workload given as "<kind>/<name>", e.g. "rollout/api"
+func GetCustomResource(ctx context.Context, client *k8s.KubernetesClient, kinds []config.Workload, namespace, resourceName string) (Workload, error) {
+	kindName, name, ok := strings.Cut(resourceName, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid resource \"%s\", expected <kind>/<name>", resourceName)
+	}
+
+	kind, err := LookupKind(kinds, kindName)
+	if err != nil {
+		return nil, err
+	}
+
+	gvr, err := getKindGroupVersionResource(client, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := client.GetUnstructured(ctx, gvr, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCustomResource(client, object, kind), nil
+}
+
+func FindKind(kinds []config.Workload, gvk schema.GroupVersionKind) (*config.Workload, error) {
+	for i, kind := range kinds {
+		if kind.Group == gvk.Group && kind.Kind == gvk.Kind {
+			return &kinds[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrWorkloadNotConfigured, gvk.GroupKind())
+}
+
+// LookupKind matches the kind, the resource, or either of them qualified by the group
+func LookupKind(kinds []config.Workload, name string) (*config.Workload, error) {
+	for i, kind := range kinds {
+		for _, kindName := range []string{kind.Kind, kind.Resource} {
+			if strings.EqualFold(name, kindName) || strings.EqualFold(name, kindName+"."+kind.Group) {
+				return &kinds[i], nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrWorkloadNotConfigured, name)
+}
+
+func getKindGroupVersionResource(client *k8s.KubernetesClient, kind *config.Workload) (schema.GroupVersionResource, error) {
+	if kind.Version != "" {
+		return schema.GroupVersionResource{
+			Group:    kind.Group,
+			Version:  kind.Version,
+			Resource: kind.Resource,
+		}, nil
+	}
+
+	return client.GetPreferredGroupVersionResource(kind.Group, kind.Resource)
+}
+
+// listCustomResources skips the workload kinds not installed in the cluster or not accessible
+func listCustomResources(ctx context.Context, client *k8s.KubernetesClient, namespace string, kinds []config.Workload) ([]Workload, error) {
+	workloads := []Workload{}
+	for i := range kinds {
+		gvr, err := getKindGroupVersionResource(client, &kinds[i])
+		if errors.Is(err, k8s.ErrAPIGroupNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		list, err := client.ListUnstructured(ctx, gvr, namespace)
+		if apiErrors.IsNotFound(err) || apiErrors.IsForbidden(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for j := range list.Items {
+			workloads = append(workloads, NewCustomResource(client, &list.Items[j], &kinds[i]))
+		}
+	}
+
+	return workloads, nil
+}
+
+// getGroupVersionResource uses the version the resource was read with
+func (w *customResource) getGroupVersionResource() schema.GroupVersionResource {
+	return w.GroupVersionKind().GroupVersion().WithResource(w.kind.Resource)
+}
+
+func (w *customResource) GetResourceType() ResourceType {
+	return CustomResource
+}
+
+func (w *customResource) GetKind() string {
+	return w.kind.Kind
+}
+
+func (w *customResource) GetObject() Resource {
+	return w.Unstructured
+}
+
+func (w *customResource) Get(ctx context.Context) (Workload, error) {
+	object, err := w.client.GetUnstructured(ctx, w.getGroupVersionResource(), w.GetNamespace(), w.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCustomResource(w.client, object, w.kind), nil
+}
+
+func (w *customResource) Watch(ctx context.Context, listOptions apiMetaV1.ListOptions) (watch.Interface, error) {
+	return w.client.WatchUnstructured(ctx, w.getGroupVersionResource(), w.GetNamespace(), listOptions)
+}
+
+func (w *customResource) Delete(ctx context.Context) error {
+	return notSupportedError(w, "delete")
+}
+
+func (w *customResource) Clone(ctx context.Context, objectMeta apiMetaV1.ObjectMeta, cloneFunc CloneFunc) (Workload, error) {
+	return nil, notSupportedError(w, "clone")
+}
+
+func (w *customResource) GetSnapshot() (string, error) {
+	return getSnapshot(w.Unstructured)
+}
+
+func (w *customResource) GetPatch() (patch.Resource, error) {
+	var replicas int32 = 1
+	return &patch.CustomResourcePatchConfiguration{
+		ObjectMetaApplyConfiguration: &applyMetaV1.ObjectMetaApplyConfiguration{},
+		Replicas:                     &replicas,
+		PodTemplatePath:              k8sTools.SplitFieldPath(w.kind.PodTemplatePath),
+		ReplicasPath:                 k8sTools.SplitFieldPath(w.kind.ReplicasPath),
+	}, nil
+}
+
+// Patch merges the patch client side and updates the live resource, since custom resources only
+// support JSON merge patches which would replace the container lists
+func (w *customResource) Patch(ctx context.Context, data []byte) error {
+	return w.update(ctx, data, false)
+}
+
+func (w *customResource) update(ctx context.Context, data []byte, dryRun bool) error {
+	gvr := w.getGroupVersionResource()
+	object, err := w.client.GetUnstructured(ctx, gvr, w.GetNamespace(), w.GetName())
+	if err != nil {
+		return err
+	}
+
+	resourcePatch := map[string]any{}
+	if err := json.Unmarshal(data, &resourcePatch); err != nil {
+		return err
+	}
+
+	if err := k8sTools.ApplyUnstructuredPatch(object, resourcePatch, w.kind.PodTemplatePath); err != nil {
+		return err
+	}
+
+	_, err = w.client.UpdateUnstructured(ctx, gvr, w.GetNamespace(), object, dryRun)
+	return err
+}
+
+func (w *customResource) BatchPatch(ctx context.Context, data []byte) error {
+	return w.client.BatchPatchUnstructured(ctx, w.getGroupVersionResource(), w.GetNamespace(), w.GetName(), data)
+}
+
+// Apply merges the patch client side as well: without list merge keys in the schema, a server-side
+// apply of custom resources would replace the container lists; a dry run is a server-side dry run update
+func (w *customResource) Apply(ctx context.Context, data []byte, options ApplyOptions) error {
+	return w.update(ctx, data, options.DryRun)
+}
+
+// TakeOver removes the container fields, the client side merge does not drop the fields left out
+func (w *customResource) TakeOver(ctx context.Context, containerName string, fields []string, data []byte) error {
+	return takeOver(ctx, w, containerName, fields, data)
+}
+
+func (w *customResource) GetTakeOverPatch(ctx context.Context, containerName string, fields []string, data []byte) ([]byte, error) {
+	return getTakeOverPatch(ctx, w, containerName, fields, data)
+}
+
+func (w *customResource) Restore(ctx context.Context, manifest string) error {
+	object := &unstructured.Unstructured{}
+	if err := object.UnmarshalJSON([]byte(manifest)); err != nil {
+		return err
+	}
+
+	gvr := w.getGroupVersionResource()
+	live, err := w.client.GetUnstructured(ctx, gvr, object.GetNamespace(), object.GetName())
+	if err != nil {
+		return err
+	}
+
+	// custom resources do not allow unconditional updates
+	object.SetResourceVersion(live.GetResourceVersion())
+
+	_, err = w.client.UpdateUnstructured(ctx, gvr, object.GetNamespace(), object, false)
+	return err
+}
+
+// GetRestorePatch returns the manifest, custom resources are replaced by Restore
+func (w *customResource) GetRestorePatch(ctx context.Context, manifest string) ([]byte, error) {
+	return []byte(manifest), nil
+}
+
+func (w *customResource) GetSchema() (any, error) {
+	return nil, ErrNoSchema
+}
+
+func (w *customResource) GetSelector() (*apiMetaV1.LabelSelector, error) {
+	return k8sTools.GetUnstructuredSelector(w.Unstructured, w.kind.SelectorPath, w.kind.SelectorLabel)
+}
+
+func (w *customResource) GetPodTemplatePath() string {
+	return k8sTools.GetJSONPointer(w.kind.PodTemplatePath)
+}
+
+func (w *customResource) GetContainers() ([]coreV1.Container, error) {
+	return k8sTools.GetUnstructuredContainers(w.Unstructured, w.kind.PodTemplatePath)
+}
+
+func (w *customResource) GetInitContainers() ([]coreV1.Container, error) {
+	return k8sTools.GetUnstructuredInitContainers(w.Unstructured, w.kind.PodTemplatePath)
+}
+
+func (w *customResource) GetPodSpec() (*coreV1.PodSpec, error) {
+	podTemplate, err := k8sTools.GetUnstructuredPodTemplate(w.Unstructured, w.kind.PodTemplatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &podTemplate.Spec, nil
+}
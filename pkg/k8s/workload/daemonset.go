@@ -0,0 +1,138 @@
+package workload
+
+import (
+	"context"
+
+	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/k8s/patch"
+	k8sTools "bunnyshell.com/dev/pkg/k8s/tools"
+
+	appsV1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	applyMetaV1 "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+type daemonSet struct {
+	*appsV1.DaemonSet
+
+	client *k8s.KubernetesClient
+}
+
+func NewDaemonSet(client *k8s.KubernetesClient, object *appsV1.DaemonSet) Workload {
+	return &daemonSet{DaemonSet: object, client: client}
+}
+
+func (w *daemonSet) GetResourceType() ResourceType {
+	return DaemonSet
+}
+
+func (w *daemonSet) GetAPIVersion() string {
+	return "apps/v1"
+}
+
+func (w *daemonSet) GetKind() string {
+	return "DaemonSet"
+}
+
+func (w *daemonSet) GetObject() Resource {
+	return w.DaemonSet
+}
+
+func (w *daemonSet) Get(ctx context.Context) (Workload, error) {
+	return Get(ctx, w.client, DaemonSet, w.GetNamespace(), w.GetName())
+}
+
+func (w *daemonSet) Watch(ctx context.Context, listOptions apiMetaV1.ListOptions) (watch.Interface, error) {
+	return w.client.WatchDaemonSets(ctx, w.GetNamespace(), listOptions)
+}
+
+func (w *daemonSet) Delete(ctx context.Context) error {
+	return w.client.DeleteDaemonSet(ctx, w.GetNamespace(), w.GetName())
+}
+
+func (w *daemonSet) Clone(ctx context.Context, objectMeta apiMetaV1.ObjectMeta, cloneFunc CloneFunc) (Workload, error) {
+	clone := &appsV1.DaemonSet{ObjectMeta: objectMeta, Spec: *w.Spec.DeepCopy()}
+	selector, err := cloneFunc(NewDaemonSet(w.client, clone), clone.Spec.Selector, &clone.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+	clone.Spec.Selector = selector
+
+	object, err := w.client.CreateDaemonSet(ctx, objectMeta.Namespace, clone)
+	if apiErrors.IsAlreadyExists(err) {
+		object, err = w.client.GetDaemonSet(ctx, objectMeta.Namespace, objectMeta.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDaemonSet(w.client, object), nil
+}
+
+func (w *daemonSet) GetSnapshot() (string, error) {
+	return getSnapshot(w.DaemonSet)
+}
+
+// GetPatch keeps the update strategy, the OnDelete strategy would never replace the pods with the dev pod
+func (w *daemonSet) GetPatch() (patch.Resource, error) {
+	return &patch.DaemonSetPatchConfiguration{
+		TypeMetaApplyConfiguration:   *applyMetaV1.TypeMeta().WithAPIVersion(w.GetAPIVersion()).WithKind(w.GetKind()),
+		ObjectMetaApplyConfiguration: applyMetaV1.ObjectMeta().WithName(w.GetName()).WithNamespace(w.GetNamespace()),
+		Spec:                         &patch.DaemonSetSpecPatchConfiguration{},
+	}, nil
+}
+
+func (w *daemonSet) Patch(ctx context.Context, data []byte) error {
+	return w.client.PatchDaemonSet(ctx, w.GetNamespace(), w.GetName(), data)
+}
+
+func (w *daemonSet) BatchPatch(ctx context.Context, data []byte) error {
+	return w.client.BatchPatchDaemonSet(ctx, w.GetNamespace(), w.GetName(), data)
+}
+
+func (w *daemonSet) Apply(ctx context.Context, data []byte, options ApplyOptions) error {
+	return w.client.ApplyDaemonSet(ctx, w.GetNamespace(), w.GetName(), data, options.Force, options.DryRun)
+}
+
+func (w *daemonSet) TakeOver(ctx context.Context, containerName string, fields []string, data []byte) error {
+	return takeOver(ctx, w, containerName, fields, data)
+}
+
+func (w *daemonSet) GetTakeOverPatch(ctx context.Context, containerName string, fields []string, data []byte) ([]byte, error) {
+	return getTakeOverPatch(ctx, w, containerName, fields, data)
+}
+
+func (w *daemonSet) Restore(ctx context.Context, manifest string) error {
+	return restore(ctx, w, manifest)
+}
+
+func (w *daemonSet) GetRestorePatch(ctx context.Context, manifest string) ([]byte, error) {
+	return getReleasePatch(ctx, w, manifest)
+}
+
+func (w *daemonSet) GetSchema() (any, error) {
+	return appsV1.DaemonSet{}, nil
+}
+
+func (w *daemonSet) GetSelector() (*apiMetaV1.LabelSelector, error) {
+	return w.Spec.Selector, nil
+}
+
+func (w *daemonSet) GetPodTemplatePath() string {
+	return "/spec/template"
+}
+
+func (w *daemonSet) GetContainers() ([]coreV1.Container, error) {
+	return k8sTools.GetDaemonSetContainers(w.DaemonSet), nil
+}
+
+func (w *daemonSet) GetInitContainers() ([]coreV1.Container, error) {
+	return k8sTools.GetDaemonSetInitContainers(w.DaemonSet), nil
+}
+
+func (w *daemonSet) GetPodSpec() (*coreV1.PodSpec, error) {
+	return &w.Spec.Template.Spec, nil
+}
@@ -0,0 +1,148 @@
+package workload
+
+import (
+	"context"
+
+	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/k8s/patch"
+	k8sTools "bunnyshell.com/dev/pkg/k8s/tools"
+
+	appsV1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	applyMetaV1 "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+type deployment struct {
+	*appsV1.Deployment
+
+	client *k8s.KubernetesClient
+}
+
+func NewDeployment(client *k8s.KubernetesClient, object *appsV1.Deployment) Workload {
+	return &deployment{Deployment: object, client: client}
+}
+
+func (w *deployment) GetResourceType() ResourceType {
+	return Deployment
+}
+
+func (w *deployment) GetAPIVersion() string {
+	return "apps/v1"
+}
+
+func (w *deployment) GetKind() string {
+	return "Deployment"
+}
+
+func (w *deployment) GetObject() Resource {
+	return w.Deployment
+}
+
+func (w *deployment) Get(ctx context.Context) (Workload, error) {
+	return Get(ctx, w.client, Deployment, w.GetNamespace(), w.GetName())
+}
+
+func (w *deployment) Watch(ctx context.Context, listOptions apiMetaV1.ListOptions) (watch.Interface, error) {
+	return w.client.WatchDeployments(ctx, w.GetNamespace(), listOptions)
+}
+
+func (w *deployment) Delete(ctx context.Context) error {
+	return w.client.DeleteDeployment(ctx, w.GetNamespace(), w.GetName())
+}
+
+func (w *deployment) Clone(ctx context.Context, objectMeta apiMetaV1.ObjectMeta, cloneFunc CloneFunc) (Workload, error) {
+	var replicas int32 = 1
+	clone := &appsV1.Deployment{ObjectMeta: objectMeta, Spec: *w.Spec.DeepCopy()}
+	clone.Spec.Replicas = &replicas
+	selector, err := cloneFunc(NewDeployment(w.client, clone), clone.Spec.Selector, &clone.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+	clone.Spec.Selector = selector
+
+	object, err := w.client.CreateDeployment(ctx, objectMeta.Namespace, clone)
+	if apiErrors.IsAlreadyExists(err) {
+		object, err = w.client.GetDeployment(ctx, objectMeta.Namespace, objectMeta.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDeployment(w.client, object), nil
+}
+
+func (w *deployment) GetSnapshot() (string, error) {
+	return getSnapshot(w.Deployment)
+}
+
+func (w *deployment) GetPatch() (patch.Resource, error) {
+	var replicas int32 = 1
+	strategy := appsV1.RecreateDeploymentStrategyType
+	return &patch.DeploymentPatchConfiguration{
+		TypeMetaApplyConfiguration:   *applyMetaV1.TypeMeta().WithAPIVersion(w.GetAPIVersion()).WithKind(w.GetKind()),
+		ObjectMetaApplyConfiguration: applyMetaV1.ObjectMeta().WithName(w.GetName()).WithNamespace(w.GetNamespace()),
+		Spec: &patch.DeploymentSpecPatchConfiguration{
+			Strategy: &patch.DeploymentStrategyPatchConfiguration{
+				Type:          &strategy,
+				RollingUpdate: nil,
+			},
+			Replicas: &replicas,
+		},
+	}, nil
+}
+
+func (w *deployment) Patch(ctx context.Context, data []byte) error {
+	return w.client.PatchDeployment(ctx, w.GetNamespace(), w.GetName(), data)
+}
+
+func (w *deployment) BatchPatch(ctx context.Context, data []byte) error {
+	return w.client.BatchPatchDeployment(ctx, w.GetNamespace(), w.GetName(), data)
+}
+
+func (w *deployment) Apply(ctx context.Context, data []byte, options ApplyOptions) error {
+	return w.client.ApplyDeployment(ctx, w.GetNamespace(), w.GetName(), data, options.Force, options.DryRun)
+}
+
+func (w *deployment) TakeOver(ctx context.Context, containerName string, fields []string, data []byte) error {
+	return takeOver(ctx, w, containerName, fields, data)
+}
+
+// GetTakeOverPatch also replaces the strategy, the rolling update parameters are invalid for the Recreate strategy of the session
+func (w *deployment) GetTakeOverPatch(ctx context.Context, containerName string, fields []string, data []byte) ([]byte, error) {
+	return getTakeOverPatch(ctx, w, containerName, fields, data, []string{"spec", "strategy"})
+}
+
+func (w *deployment) Restore(ctx context.Context, manifest string) error {
+	return restore(ctx, w, manifest)
+}
+
+func (w *deployment) GetRestorePatch(ctx context.Context, manifest string) ([]byte, error) {
+	return getReleasePatch(ctx, w, manifest)
+}
+
+func (w *deployment) GetSchema() (any, error) {
+	return appsV1.Deployment{}, nil
+}
+
+func (w *deployment) GetSelector() (*apiMetaV1.LabelSelector, error) {
+	return w.Spec.Selector, nil
+}
+
+func (w *deployment) GetPodTemplatePath() string {
+	return "/spec/template"
+}
+
+func (w *deployment) GetContainers() ([]coreV1.Container, error) {
+	return k8sTools.GetDeploymentContainers(w.Deployment), nil
+}
+
+func (w *deployment) GetInitContainers() ([]coreV1.Container, error) {
+	return k8sTools.GetDeploymentInitContainers(w.Deployment), nil
+}
+
+func (w *deployment) GetPodSpec() (*coreV1.PodSpec, error) {
+	return &w.Spec.Template.Spec, nil
+}
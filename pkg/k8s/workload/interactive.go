@@ -0,0 +1,161 @@
+package workload
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/util"
+
+	coreV1 "k8s.io/api/core/v1"
+)
+
+var (
+	ErrNoNamespaces = fmt.Errorf("no namespaces available")
+	ErrNoResources  = fmt.Errorf("no resources available")
+	ErrNoNodes      = fmt.Errorf("no nodes available")
+
+	ErrContainerNotFound = fmt.Errorf("container not found")
+)
+
+const initContainerPrefix = "init - "
+
+func SelectNamespace(ctx context.Context, client *k8s.KubernetesClient, autoSelectSingle bool) (*coreV1.Namespace, error) {
+	namespaces, err := client.ListNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(namespaces.Items) == 0 {
+		return nil, ErrNoNamespaces
+	}
+
+	if len(namespaces.Items) == 1 && autoSelectSingle {
+		return namespaces.Items[0].DeepCopy(), nil
+	}
+
+	items := []string{}
+	for _, item := range namespaces.Items {
+		items = append(items, item.GetName())
+	}
+
+	namespace, err := util.Select("Select namespace", items)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range namespaces.Items {
+		if item.GetName() == namespace {
+			return item.DeepCopy(), nil
+		}
+	}
+
+	return nil, ErrNoNamespaces
+}
+
+// Select prompts for one of the workloads, labeled as "<kind> / <name>"
+func Select(workloads []Workload, autoSelectSingle bool) (Workload, error) {
+	if len(workloads) == 0 {
+		return nil, ErrNoResources
+	}
+
+	if len(workloads) == 1 && autoSelectSingle {
+		return workloads[0], nil
+	}
+
+	items := []string{}
+	workloadsMap := map[string]Workload{}
+	for _, workload := range workloads {
+		label := fmt.Sprintf("%s / %s", GetLabel(workload), workload.GetName())
+		items = append(items, label)
+		workloadsMap[label] = workload
+	}
+
+	selected, err := util.Select("Select resource", items)
+	if err != nil {
+		return nil, err
+	}
+
+	return workloadsMap[selected], nil
+}
+
+// SelectNode prompts for one of the nodes running the pods
+func SelectNode(pods []coreV1.Pod, autoSelectSingle bool) (string, error) {
+	items := []string{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || slices.Contains(items, pod.Spec.NodeName) {
+			continue
+		}
+
+		items = append(items, pod.Spec.NodeName)
+	}
+
+	if len(items) == 0 {
+		return "", ErrNoNodes
+	}
+
+	if len(items) == 1 && autoSelectSingle {
+		return items[0], nil
+	}
+
+	slices.Sort(items)
+
+	return util.Select("Select node", items)
+}
+
+// SelectContainer prompts for one of the containers or init containers and reports whether
+// an init container was selected; init containers are listed first, with a prefix
+func SelectContainer(containers, initContainers []coreV1.Container, autoSelectSingle bool) (*coreV1.Container, bool, error) {
+	if len(containers)+len(initContainers) == 1 && autoSelectSingle {
+		if len(containers) == 1 {
+			return &containers[0], false, nil
+		}
+
+		return &initContainers[0], true, nil
+	}
+
+	items := []string{}
+	for _, item := range initContainers {
+		items = append(items, initContainerPrefix+item.Name)
+	}
+	for _, item := range containers {
+		items = append(items, item.Name)
+	}
+
+	container, err := util.Select("Select container", items)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for i := range initContainers {
+		if initContainerPrefix+initContainers[i].Name == container {
+			return &initContainers[i], true, nil
+		}
+	}
+
+	for i := range containers {
+		if containers[i].Name == container {
+			return &containers[i], false, nil
+		}
+	}
+
+	return nil, false, ErrContainerNotFound
+}
+
+// FindContainer looks the name up in the containers, then in the init containers
+func FindContainer(containers, initContainers []coreV1.Container, name string) (*coreV1.Container, bool, error) {
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i], false, nil
+		}
+	}
+
+	for i := range initContainers {
+		if initContainers[i].Name == name {
+			return &initContainers[i], true, nil
+		}
+	}
+
+	return nil, false, fmt.Errorf("%w: %s", ErrContainerNotFound, name)
+}
@@ -0,0 +1,127 @@
+package workload
+
+import (
+	"context"
+
+	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/k8s/patch"
+	k8sTools "bunnyshell.com/dev/pkg/k8s/tools"
+
+	batchV1 "k8s.io/api/batch/v1"
+	coreV1 "k8s.io/api/core/v1"
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// job pod templates are immutable, so only the metadata is patched and sessions run on a copy
+type job struct {
+	*batchV1.Job
+
+	client *k8s.KubernetesClient
+}
+
+func NewJob(client *k8s.KubernetesClient, object *batchV1.Job) Workload {
+	return &job{Job: object, client: client}
+}
+
+func (w *job) GetResourceType() ResourceType {
+	return Job
+}
+
+func (w *job) GetAPIVersion() string {
+	return "batch/v1"
+}
+
+func (w *job) GetKind() string {
+	return "Job"
+}
+
+func (w *job) GetObject() Resource {
+	return w.Job
+}
+
+func (w *job) Get(ctx context.Context) (Workload, error) {
+	return Get(ctx, w.client, Job, w.GetNamespace(), w.GetName())
+}
+
+func (w *job) Watch(ctx context.Context, listOptions apiMetaV1.ListOptions) (watch.Interface, error) {
+	return nil, notSupportedError(w, "watch")
+}
+
+func (w *job) Delete(ctx context.Context) error {
+	return w.client.DeleteJob(ctx, w.GetNamespace(), w.GetName())
+}
+
+func (w *job) Clone(ctx context.Context, objectMeta apiMetaV1.ObjectMeta, cloneFunc CloneFunc) (Workload, error) {
+	return nil, notSupportedError(w, "clone")
+}
+
+func (w *job) GetSnapshot() (string, error) {
+	return getSnapshot(w.Job)
+}
+
+func (w *job) GetPatch() (patch.Resource, error) {
+	return nil, notSupportedError(w, "pod template patch")
+}
+
+func (w *job) Patch(ctx context.Context, data []byte) error {
+	return w.client.PatchJob(ctx, w.GetNamespace(), w.GetName(), data)
+}
+
+func (w *job) BatchPatch(ctx context.Context, data []byte) error {
+	return notSupportedError(w, "json patch")
+}
+
+func (w *job) Apply(ctx context.Context, data []byte, options ApplyOptions) error {
+	return notSupportedError(w, "apply")
+}
+
+func (w *job) TakeOver(ctx context.Context, containerName string, fields []string, data []byte) error {
+	return notSupportedError(w, "take over")
+}
+
+func (w *job) GetTakeOverPatch(ctx context.Context, containerName string, fields []string, data []byte) ([]byte, error) {
+	return nil, notSupportedError(w, "take over")
+}
+
+func (w *job) Restore(ctx context.Context, manifest string) error {
+	return notSupportedError(w, "restore")
+}
+
+func (w *job) GetRestorePatch(ctx context.Context, manifest string) ([]byte, error) {
+	return nil, notSupportedError(w, "restore")
+}
+
+func (w *job) GetSchema() (any, error) {
+	return batchV1.Job{}, nil
+}
+
+func (w *job) GetSelector() (*apiMetaV1.LabelSelector, error) {
+	return w.Spec.Selector, nil
+}
+
+func (w *job) GetPodTemplatePath() string {
+	return "/spec/template"
+}
+
+func (w *job) GetContainers() ([]coreV1.Container, error) {
+	return k8sTools.GetJobContainers(w.Job), nil
+}
+
+func (w *job) GetInitContainers() ([]coreV1.Container, error) {
+	return w.Spec.Template.Spec.InitContainers, nil
+}
+
+func isOwnedByCronJob(job *batchV1.Job) bool {
+	for _, ownerReference := range job.GetOwnerReferences() {
+		if ownerReference.Kind == "CronJob" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *job) GetPodSpec() (*coreV1.PodSpec, error) {
+	return &w.Spec.Template.Spec, nil
+}
@@ -0,0 +1,86 @@
+package workload
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/util"
+
+	coreV1 "k8s.io/api/core/v1"
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var ErrPodWaitTimeout = fmt.Errorf("timeout waiting for pod")
+
+// PodCondition reports whether a pod is in the state being waited for
+type PodCondition func(pod *coreV1.Pod) bool
+
+// GetPodListOptions lists the pods matched by both the labels and the expressions of the selector
+func GetPodListOptions(selector *apiMetaV1.LabelSelector) (apiMetaV1.ListOptions, error) {
+	labelSelector, err := apiMetaV1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return apiMetaV1.ListOptions{}, err
+	}
+
+	return apiMetaV1.ListOptions{
+		LabelSelector: labelSelector.String(),
+	}, nil
+}
+
+func ListPods(ctx context.Context, client *k8s.KubernetesClient, namespace string, selector *apiMetaV1.LabelSelector) ([]coreV1.Pod, error) {
+	listOptions, err := GetPodListOptions(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	podList, err := client.ListPods(ctx, namespace, listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return podList.Items, nil
+}
+
+// FindPod returns the first pod matched by the selector which meets the condition
+func FindPod(ctx context.Context, client *k8s.KubernetesClient, namespace string, selector *apiMetaV1.LabelSelector, condition PodCondition) (*coreV1.Pod, error) {
+	pods, err := ListPods(ctx, client, namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range pods {
+		if condition(&pods[i]) {
+			return &pods[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// WaitPod polls the pods matched by the selector until one of them meets the condition
+func WaitPod(ctx context.Context, client *k8s.KubernetesClient, namespace string, selector *apiMetaV1.LabelSelector, waitTimeout int64, condition PodCondition) (*coreV1.Pod, error) {
+	startTimestamp := time.Now().Unix()
+	for {
+		if err := util.Sleep(ctx, 1*time.Second); err != nil {
+			return nil, err
+		}
+
+		pod, err := FindPod(ctx, client, namespace, selector, condition)
+		if err != nil {
+			return nil, err
+		}
+
+		if pod != nil {
+			return pod, nil
+		}
+
+		nowTimestamp := time.Now().Unix()
+		if nowTimestamp-startTimestamp >= waitTimeout {
+			break
+		}
+	}
+
+	return nil, ErrPodWaitTimeout
+}
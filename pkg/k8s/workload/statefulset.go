@@ -0,0 +1,144 @@
+package workload
+
+import (
+	"context"
+
+	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/k8s/patch"
+	k8sTools "bunnyshell.com/dev/pkg/k8s/tools"
+
+	appsV1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	applyMetaV1 "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+type statefulSet struct {
+	*appsV1.StatefulSet
+
+	client *k8s.KubernetesClient
+}
+
+func NewStatefulSet(client *k8s.KubernetesClient, object *appsV1.StatefulSet) Workload {
+	return &statefulSet{StatefulSet: object, client: client}
+}
+
+func (w *statefulSet) GetResourceType() ResourceType {
+	return StatefulSet
+}
+
+func (w *statefulSet) GetAPIVersion() string {
+	return "apps/v1"
+}
+
+func (w *statefulSet) GetKind() string {
+	return "StatefulSet"
+}
+
+func (w *statefulSet) GetObject() Resource {
+	return w.StatefulSet
+}
+
+func (w *statefulSet) Get(ctx context.Context) (Workload, error) {
+	return Get(ctx, w.client, StatefulSet, w.GetNamespace(), w.GetName())
+}
+
+func (w *statefulSet) Watch(ctx context.Context, listOptions apiMetaV1.ListOptions) (watch.Interface, error) {
+	return w.client.WatchStatefulSets(ctx, w.GetNamespace(), listOptions)
+}
+
+func (w *statefulSet) Delete(ctx context.Context) error {
+	return w.client.DeleteStatefulSet(ctx, w.GetNamespace(), w.GetName())
+}
+
+func (w *statefulSet) Clone(ctx context.Context, objectMeta apiMetaV1.ObjectMeta, cloneFunc CloneFunc) (Workload, error) {
+	var replicas int32 = 1
+	clone := &appsV1.StatefulSet{ObjectMeta: objectMeta, Spec: *w.Spec.DeepCopy()}
+	clone.Spec.Replicas = &replicas
+	selector, err := cloneFunc(NewStatefulSet(w.client, clone), clone.Spec.Selector, &clone.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+	clone.Spec.Selector = selector
+
+	object, err := w.client.CreateStatefulSet(ctx, objectMeta.Namespace, clone)
+	if apiErrors.IsAlreadyExists(err) {
+		object, err = w.client.GetStatefulSet(ctx, objectMeta.Namespace, objectMeta.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStatefulSet(w.client, object), nil
+}
+
+func (w *statefulSet) GetSnapshot() (string, error) {
+	return getSnapshot(w.StatefulSet)
+}
+
+// GetPatch keeps the update strategy, the OnDelete strategy would never replace the pods with the dev pod
+func (w *statefulSet) GetPatch() (patch.Resource, error) {
+	var replicas int32 = 1
+	return &patch.StatefulSetPatchConfiguration{
+		TypeMetaApplyConfiguration:   *applyMetaV1.TypeMeta().WithAPIVersion(w.GetAPIVersion()).WithKind(w.GetKind()),
+		ObjectMetaApplyConfiguration: applyMetaV1.ObjectMeta().WithName(w.GetName()).WithNamespace(w.GetNamespace()),
+		Spec: &patch.StatefulSetSpecPatchConfiguration{
+			Replicas: &replicas,
+		},
+	}, nil
+}
+
+func (w *statefulSet) Patch(ctx context.Context, data []byte) error {
+	return w.client.PatchStatefulSet(ctx, w.GetNamespace(), w.GetName(), data)
+}
+
+func (w *statefulSet) BatchPatch(ctx context.Context, data []byte) error {
+	return w.client.BatchPatchStatefulSet(ctx, w.GetNamespace(), w.GetName(), data)
+}
+
+func (w *statefulSet) Apply(ctx context.Context, data []byte, options ApplyOptions) error {
+	return w.client.ApplyStatefulSet(ctx, w.GetNamespace(), w.GetName(), data, options.Force, options.DryRun)
+}
+
+func (w *statefulSet) TakeOver(ctx context.Context, containerName string, fields []string, data []byte) error {
+	return takeOver(ctx, w, containerName, fields, data)
+}
+
+// GetTakeOverPatch also replaces the update strategy with the rolling update partitioned at a pinned ordinal
+func (w *statefulSet) GetTakeOverPatch(ctx context.Context, containerName string, fields []string, data []byte) ([]byte, error) {
+	return getTakeOverPatch(ctx, w, containerName, fields, data, []string{"spec", "updateStrategy"})
+}
+
+func (w *statefulSet) Restore(ctx context.Context, manifest string) error {
+	return restore(ctx, w, manifest)
+}
+
+func (w *statefulSet) GetRestorePatch(ctx context.Context, manifest string) ([]byte, error) {
+	return getReleasePatch(ctx, w, manifest)
+}
+
+func (w *statefulSet) GetSchema() (any, error) {
+	return appsV1.StatefulSet{}, nil
+}
+
+func (w *statefulSet) GetSelector() (*apiMetaV1.LabelSelector, error) {
+	return w.Spec.Selector, nil
+}
+
+func (w *statefulSet) GetPodTemplatePath() string {
+	return "/spec/template"
+}
+
+func (w *statefulSet) GetContainers() ([]coreV1.Container, error) {
+	return k8sTools.GetStatefulSetContainers(w.StatefulSet), nil
+}
+
+func (w *statefulSet) GetInitContainers() ([]coreV1.Container, error) {
+	return k8sTools.GetStatefulSetInitContainers(w.StatefulSet), nil
+}
+
+func (w *statefulSet) GetPodSpec() (*coreV1.PodSpec, error) {
+	return &w.Spec.Template.Spec, nil
+}
@@ -0,0 +1,283 @@
+package workload
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"bunnyshell.com/dev/pkg/config"
+	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/k8s/patch"
+
+	appsV1 "k8s.io/api/apps/v1"
+	batchV1 "k8s.io/api/batch/v1"
+	coreV1 "k8s.io/api/core/v1"
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// +enum
+type ResourceType string
+
+const (
+	Deployment  ResourceType = "deployment"
+	StatefulSet ResourceType = "statefulset"
+	DaemonSet   ResourceType = "daemonset"
+	Job         ResourceType = "job"
+	CronJob     ResourceType = "cronjob"
+
+	// CustomResource is any workload kind mapped in the configuration
+	CustomResource ResourceType = "custom"
+)
+
+const (
+	MetadataKubeCTLLastAppliedConf = "kubectl.kubernetes.io/last-applied-configuration"
+	MetadataK8SRevision            = "deployment.kubernetes.io/revision"
+)
+
+var (
+	ErrInvalidResourceType = fmt.Errorf("invalid resource type")
+	ErrNotSupported        = fmt.Errorf("operation not supported")
+	ErrNoSchema            = fmt.Errorf("no strategic merge schema available")
+)
+
+type Resource interface {
+	GetName() string
+	GetNamespace() string
+	GetAnnotations() map[string]string
+	GetLabels() map[string]string
+	GetGeneration() int64
+	GetResourceVersion() string
+	GetManagedFields() []apiMetaV1.ManagedFieldsEntry
+}
+
+// CloneFunc returns the selector of a clone and updates its pod template, e.g. the pod labels;
+// the clone is not created yet
+type CloneFunc func(clone Workload, selector *apiMetaV1.LabelSelector, podTemplate *coreV1.PodTemplateSpec) (*apiMetaV1.LabelSelector, error)
+
+// Workload adapts a workload kind to the operations needed by remote-dev and debug sessions
+type Workload interface {
+	Resource
+
+	GetResourceType() ResourceType
+	GetAPIVersion() string
+	GetKind() string
+	GetObject() Resource
+
+	// Get reads the live workload
+	Get(ctx context.Context) (Workload, error)
+	Watch(ctx context.Context, listOptions apiMetaV1.ListOptions) (watch.Interface, error)
+	Delete(ctx context.Context) error
+	// Clone creates a copy running a single replica, or returns the existing one
+	Clone(ctx context.Context, objectMeta apiMetaV1.ObjectMeta, cloneFunc CloneFunc) (Workload, error)
+
+	// GetSnapshot returns the manifest used to restore the workload
+	GetSnapshot() (string, error)
+	// GetPatch returns the base patch running a single pod, completed with the pod template by the session
+	GetPatch() (patch.Resource, error)
+	Patch(ctx context.Context, data []byte) error
+	BatchPatch(ctx context.Context, data []byte) error
+	// Apply runs a server-side apply of the bunnyshell-dev field manager
+	Apply(ctx context.Context, data []byte, options ApplyOptions) error
+	// TakeOver removes the container fields dropped by the apply data and replaces the atomic resource fields
+	// with the applied ones, so the next apply is not merged with the values of other field managers
+	TakeOver(ctx context.Context, containerName string, fields []string, data []byte) error
+	// GetTakeOverPatch returns the JSON patch written by TakeOver, nil when there is nothing to take over
+	GetTakeOverPatch(ctx context.Context, containerName string, fields []string, data []byte) ([]byte, error)
+	// Restore reverts the workload to the manifest and relinquishes the fields of the bunnyshell-dev field manager
+	Restore(ctx context.Context, manifest string) error
+	// GetRestorePatch returns the patch written by Restore
+	GetRestorePatch(ctx context.Context, manifest string) ([]byte, error)
+	GetSchema() (any, error)
+
+	GetSelector() (*apiMetaV1.LabelSelector, error)
+	// GetPodTemplatePath returns the JSON patch path of the pod template
+	GetPodTemplatePath() string
+	GetContainers() ([]coreV1.Container, error)
+	GetInitContainers() ([]coreV1.Container, error)
+	GetPodSpec() (*coreV1.PodSpec, error)
+}
+
+func New(client *k8s.KubernetesClient, resource Resource, kinds []config.Workload) (Workload, error) {
+	switch object := resource.(type) {
+	case *appsV1.Deployment:
+		return NewDeployment(client, object), nil
+	case *appsV1.StatefulSet:
+		return NewStatefulSet(client, object), nil
+	case *appsV1.DaemonSet:
+		return NewDaemonSet(client, object), nil
+	case *batchV1.Job:
+		return NewJob(client, object), nil
+	case *batchV1.CronJob:
+		return NewCronJob(client, object), nil
+	case *unstructured.Unstructured:
+		kind, err := FindKind(kinds, object.GroupVersionKind())
+		if err != nil {
+			return nil, err
+		}
+
+		return NewCustomResource(client, object, kind), nil
+	case Workload:
+		return object, nil
+	default:
+		return nil, ErrInvalidResourceType
+	}
+}
+
+// Get reads a workload of one of the built-in kinds
+func Get(ctx context.Context, client *k8s.KubernetesClient, resourceType ResourceType, namespace, name string) (Workload, error) {
+	switch resourceType {
+	case Deployment:
+		deployment, err := client.GetDeployment(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewDeployment(client, deployment), nil
+	case StatefulSet:
+		statefulSet, err := client.GetStatefulSet(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewStatefulSet(client, statefulSet), nil
+	case DaemonSet:
+		daemonSet, err := client.GetDaemonSet(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewDaemonSet(client, daemonSet), nil
+	case Job:
+		job, err := client.GetJob(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewJob(client, job), nil
+	case CronJob:
+		cronJob, err := client.GetCronJob(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewCronJob(client, cronJob), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidResourceType, resourceType)
+	}
+}
+
+// List returns the workloads of the given types, custom resources included when CustomResource is requested
+func List(ctx context.Context, client *k8s.KubernetesClient, namespace string, kinds []config.Workload, resourceTypes ...ResourceType) ([]Workload, error) {
+	workloads := []Workload{}
+	for _, resourceType := range resourceTypes {
+		items, err := list(ctx, client, namespace, kinds, resourceType)
+		if err != nil {
+			return nil, err
+		}
+
+		workloads = append(workloads, items...)
+	}
+
+	return workloads, nil
+}
+
+func list(ctx context.Context, client *k8s.KubernetesClient, namespace string, kinds []config.Workload, resourceType ResourceType) ([]Workload, error) {
+	workloads := []Workload{}
+
+	switch resourceType {
+	case Deployment:
+		deployments, err := client.ListDeployments(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for i := range deployments.Items {
+			workloads = append(workloads, NewDeployment(client, &deployments.Items[i]))
+		}
+	case StatefulSet:
+		statefulSets, err := client.ListStatefulSets(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for i := range statefulSets.Items {
+			workloads = append(workloads, NewStatefulSet(client, &statefulSets.Items[i]))
+		}
+	case DaemonSet:
+		daemonSets, err := client.ListDaemonSets(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for i := range daemonSets.Items {
+			workloads = append(workloads, NewDaemonSet(client, &daemonSets.Items[i]))
+		}
+	case Job:
+		jobs, err := client.ListJobs(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for i := range jobs.Items {
+			if isOwnedByCronJob(&jobs.Items[i]) {
+				continue
+			}
+
+			workloads = append(workloads, NewJob(client, &jobs.Items[i]))
+		}
+	case CronJob:
+		cronJobs, err := client.ListCronJobs(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for i := range cronJobs.Items {
+			workloads = append(workloads, NewCronJob(client, &cronJobs.Items[i]))
+		}
+	case CustomResource:
+		return listCustomResources(ctx, client, namespace, kinds)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidResourceType, resourceType)
+	}
+
+	return workloads, nil
+}
+
+// GetLabel returns the lowercase kind, e.g. "deployment" or "rollout"
+func GetLabel(workload Workload) string {
+	return strings.ToLower(workload.GetKind())
+}
+
+// getSnapshot strips the data populated by the API server from the manifest
+func getSnapshot(object any) (string, error) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return "", err
+	}
+
+	manifest := map[string]any{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", err
+	}
+
+	delete(manifest, "status")
+	if metadata, ok := manifest["metadata"].(map[string]any); ok {
+		for _, field := range []string{"generation", "uid", "resourceVersion", "managedFields", "creationTimestamp"} {
+			delete(metadata, field)
+		}
+
+		if annotations, ok := metadata["annotations"].(map[string]any); ok {
+			delete(annotations, MetadataK8SRevision)
+			delete(annotations, MetadataKubeCTLLastAppliedConf)
+		}
+	}
+
+	snapshot, err := json.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+
+	return string(snapshot), nil
+}
+
+func notSupportedError(workload Workload, operation string) error {
+	return fmt.Errorf("%w: %s for %s", ErrNotSupported, operation, GetLabel(workload))
+}
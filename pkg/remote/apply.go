@@ -0,0 +1,65 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+
+	"bunnyshell.com/dev/pkg/k8s/workload"
+	"bunnyshell.com/dev/pkg/util"
+)
+
+var ErrFieldConflicts = fmt.Errorf("fields owned by other field managers not taken over")
+
+// resetContainerFields are dropped from the dev container, which runs the remote-dev start script instead
+var resetContainerFields = []string{"args", "env", "readinessProbe", "livenessProbe", "startupProbe"}
+
+// applyResource writes the session changes with a server-side apply of the bunnyshell-dev field manager, once
+// the fields the apply cannot drop are removed; the fields owned by other field managers are only taken over
+// when the user agrees
+func (r *RemoteDevelopment) applyResource(ctx context.Context, resource workload.Workload, data []byte) error {
+	err := resource.Apply(ctx, data, workload.ApplyOptions{DryRun: true})
+	conflicts := workload.GetFieldConflicts(err)
+	if err != nil && conflicts == nil {
+		return err
+	}
+
+	if err := r.confirmFieldConflicts(resource, conflicts); err != nil {
+		return err
+	}
+
+	if err := resource.TakeOver(ctx, r.container.Name, resetContainerFields, data); err != nil {
+		return fmt.Errorf("cannot reset container: %w", err)
+	}
+
+	return resource.Apply(ctx, data, workload.ApplyOptions{Force: true})
+}
+
+func (r *RemoteDevelopment) confirmFieldConflicts(resource workload.Workload, conflicts []workload.FieldConflict) error {
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	r.StopSpinner()
+	defer r.StartSpinner("")
+
+	fmt.Printf("WARNING: %s \"%s\" fields are owned by other field managers:\n", workload.GetLabel(resource), resource.GetName())
+	for _, conflict := range conflicts {
+		fmt.Printf("  %s (%s)\n", conflict.Field, conflict.Message)
+	}
+
+	// the dry run only lists the conflicts, it must not block on a prompt
+	if r.forceConflicts || r.dryRun == DryRunServer {
+		return nil
+	}
+
+	confirmed, err := util.Confirm("Take over these fields for the session")
+	if err != nil {
+		return err
+	}
+
+	if !confirmed {
+		return ErrFieldConflicts
+	}
+
+	return nil
+}
@@ -0,0 +1,286 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"regexp"
+	"strings"
+
+	"bunnyshell.com/dev/pkg/k8s/workload"
+
+	coreV1 "k8s.io/api/core/v1"
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/validation"
+	applyCoreV1 "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+const (
+	MetadataClone   = MetadataPrefix + "clone"
+	MetadataCloneOf = MetadataPrefix + "clone-of"
+
+	cloneNameFormat = "%s-dev-%s"
+)
+
+var invalidNameCharsExp = regexp.MustCompile("[^a-z0-9-]+")
+
+var ErrNoUsername = fmt.Errorf("cannot determine the current user, set the USER environment variable")
+
+// metadata that must not be copied to the clone, so GitOps controllers don't adopt or prune it
+var cloneExcludedMetadata = []string{
+	ArgoCDTrackingIdAnnotation,
+	ArgoCDInstanceLabel,
+	FluxKustomizationNameLabel,
+	FluxKustomizationNamespaceLabel,
+	FluxHelmReleaseNameLabel,
+	FluxHelmReleaseNamespaceLabel,
+	MetadataKubeCTLLastAppliedConf,
+	MetadataK8SRevision,
+}
+
+func (r *RemoteDevelopment) getCloneName(resource Resource) (string, error) {
+	username, err := getUsername()
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf(cloneNameFormat, resource.GetName(), sanitizeName(username))
+	if len(name) > validation.DNS1123LabelMaxLength {
+		name = name[:validation.DNS1123LabelMaxLength]
+	}
+
+	return strings.TrimRight(name, "-"), nil
+}
+
+// getUsername falls back to the environment when there is no user database, e.g. in distroless containers
+func getUsername() (string, error) {
+	if currentUser, err := user.Current(); err == nil && currentUser.Username != "" {
+		return currentUser.Username, nil
+	}
+
+	for _, name := range []string{"USER", "USERNAME"} {
+		if username := os.Getenv(name); username != "" {
+			return username, nil
+		}
+	}
+
+	return "", ErrNoUsername
+}
+
+func sanitizeName(value string) string {
+	// DOMAIN\user on windows
+	if index := strings.LastIndex(value, "\\"); index >= 0 {
+		value = value[index+1:]
+	}
+
+	return strings.Trim(invalidNameCharsExp.ReplaceAllString(strings.ToLower(value), "-"), "-")
+}
+
+// ensureClone creates a copy of the selected resource and makes it the remote-dev target
+func (r *RemoteDevelopment) ensureClone(ctx context.Context) error {
+	r.StartSpinner(" Clone resource for remote development")
+	defer r.StopSpinner()
+
+	resource, err := r.getResource()
+	if err != nil {
+		return err
+	}
+
+	cloneName, err := r.getCloneName(resource)
+	if err != nil {
+		return err
+	}
+
+	// a clone left by a previous session is reused and kept on failure
+	_, err = workload.Get(ctx, r.kubernetesClient, resource.GetResourceType(), resource.GetNamespace(), cloneName)
+	cloneExisted := err == nil
+
+	objectMeta := r.getCloneObjectMeta(resource, cloneName)
+	clone, err := resource.Clone(ctx, objectMeta, func(clone workload.Workload, selector *apiMetaV1.LabelSelector, podTemplate *coreV1.PodTemplateSpec) (*apiMetaV1.LabelSelector, error) {
+		cloneSelector, podLabels := r.getCloneSelector(selector, podTemplate.Labels, cloneName)
+		podTemplate.Labels = podLabels
+
+		// the session pod template refers to the volumes of the clone
+		r.WithWorkload(clone)
+		defer r.WithWorkload(resource)
+
+		return cloneSelector, r.prepareClonePodTemplate(podTemplate)
+	})
+	if err != nil {
+		return err
+	}
+
+	if !cloneExisted {
+		r.completeStep("clone", clone.Delete)
+	}
+	r.cloneCreated = !cloneExisted
+
+	r.WithWorkload(clone)
+	return nil
+}
+
+// prepareClonePodTemplate merges the session pod template into the clone pod template, so the clone
+// never runs the original container; a reused clone gets the session pod template from prepareResource
+func (r *RemoteDevelopment) prepareClonePodTemplate(podTemplate *coreV1.PodTemplateSpec) error {
+	sessionPodTemplate := applyCoreV1.PodTemplateSpec()
+	if err := r.preparePodTemplateSpec(sessionPodTemplate); err != nil {
+		return err
+	}
+
+	// the fields reset by the apply of the session are dropped, e.g. the probes would keep their handler
+	podTemplateData, err := json.Marshal(podTemplate)
+	if err != nil {
+		return err
+	}
+	original := map[string]any{}
+	if err := json.Unmarshal(podTemplateData, &original); err != nil {
+		return err
+	}
+	containers, _, err := unstructured.NestedSlice(original, "spec", "containers")
+	if err != nil {
+		return err
+	}
+	for _, container := range containers {
+		container, ok := container.(map[string]any)
+		if !ok || container["name"] != r.container.Name {
+			continue
+		}
+
+		for _, field := range resetContainerFields {
+			delete(container, field)
+		}
+	}
+	if err := unstructured.SetNestedSlice(original, containers, "spec", "containers"); err != nil {
+		return err
+	}
+
+	originalData, err := json.Marshal(original)
+	if err != nil {
+		return err
+	}
+	patchData, err := json.Marshal(sessionPodTemplate)
+	if err != nil {
+		return err
+	}
+
+	data, err := strategicpatch.StrategicMergePatch(originalData, patchData, coreV1.PodTemplateSpec{})
+	if err != nil {
+		return err
+	}
+
+	*podTemplate = coreV1.PodTemplateSpec{}
+	return json.Unmarshal(data, podTemplate)
+}
+
+// patchCloneMetadata marks a clone created with the session pod template as the session workload
+func (r *RemoteDevelopment) patchCloneMetadata(ctx context.Context, clone workload.Workload, annotations map[string]string) error {
+	data, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": annotations,
+			"labels": map[string]string{
+				MetadataActive: "true",
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return clone.Patch(ctx, data)
+}
+
+func (r *RemoteDevelopment) getCloneObjectMeta(resource Resource, cloneName string) apiMetaV1.ObjectMeta {
+	return apiMetaV1.ObjectMeta{
+		Name:      cloneName,
+		Namespace: resource.GetNamespace(),
+		Labels: withoutCloneExcludedMetadata(resource.GetLabels(), map[string]string{
+			MetadataClone: cloneName,
+		}),
+		Annotations: withoutCloneExcludedMetadata(resource.GetAnnotations(), map[string]string{
+			MetadataCloneOf: resource.GetName(),
+		}),
+	}
+}
+
+// getCloneSelector selects the clone pods only; unless traffic is requested, the labels matched by
+// the original selector (and most likely by the Service) are dropped from the clone pods
+func (r *RemoteDevelopment) getCloneSelector(selector *apiMetaV1.LabelSelector, podLabels map[string]string, cloneName string) (*apiMetaV1.LabelSelector, map[string]string) {
+	labels := make(map[string]string)
+	for key, value := range podLabels {
+		if _, ok := selector.MatchLabels[key]; ok && !r.cloneTraffic {
+			continue
+		}
+
+		labels[key] = value
+	}
+	labels[MetadataClone] = cloneName
+
+	return &apiMetaV1.LabelSelector{
+		MatchLabels: map[string]string{MetadataClone: cloneName},
+	}, labels
+}
+
+func withoutCloneExcludedMetadata(entries map[string]string, extra map[string]string) map[string]string {
+	result := make(map[string]string)
+	for key, value := range entries {
+		if strings.HasPrefix(key, MetadataPrefix) {
+			continue
+		}
+
+		excluded := false
+		for _, excludedKey := range cloneExcludedMetadata {
+			if key == excludedKey {
+				excluded = true
+				break
+			}
+		}
+
+		if !excluded {
+			result[key] = value
+		}
+	}
+
+	for key, value := range extra {
+		result[key] = value
+	}
+
+	return result
+}
+
+// useClone switches the target from the selected resource to its existing clone
+func (r *RemoteDevelopment) useClone(ctx context.Context) error {
+	resource, err := r.getResource()
+	if err != nil {
+		return err
+	}
+
+	cloneName, err := r.getCloneName(resource)
+	if err != nil {
+		return err
+	}
+
+	clone, err := workload.Get(ctx, r.kubernetesClient, resource.GetResourceType(), resource.GetNamespace(), cloneName)
+	if err != nil {
+		return err
+	}
+
+	r.WithWorkload(clone)
+	return nil
+}
+
+func (r *RemoteDevelopment) deleteClone(ctx context.Context) error {
+	resource, err := r.getResource()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := resource.GetAnnotations()[MetadataCloneOf]; !ok {
+		return fmt.Errorf("%s \"%s\" is not a remote-dev clone", workload.GetLabel(resource), resource.GetName())
+	}
+
+	return resource.Delete(ctx)
+}
@@ -25,6 +25,11 @@ func (c *Environ) Set(name string, value string) {
 	c.data[name] = value
 }
 
+func (c *Environ) Has(name string) bool {
+	_, ok := c.data[name]
+	return ok
+}
+
 func (c *Environ) AddFromDefinition(definition string) error {
 	name, value, err := parseDefinition(definition)
 	if err != nil {
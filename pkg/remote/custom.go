@@ -0,0 +1,35 @@
+package remote
+
+import (
+	"bunnyshell.com/dev/pkg/config"
+	"bunnyshell.com/dev/pkg/k8s/workload"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var ErrWorkloadNotConfigured = workload.ErrWorkloadNotConfigured
+
+func (r *RemoteDevelopment) WithConfig(config *config.Config) *RemoteDevelopment {
+	r.customKinds = config.GetWorkloads()
+
+	return r.
+		WithDevImage(config.Remote.Image).
+		WithImageRegistry(config.Remote.ImageRegistry).
+		WithBinariesImage(config.Remote.BinariesImage).
+		WithBinariesImageDigest(config.Remote.BinariesImageDigest).
+		WithImagePullSecrets(config.Remote.ImagePullSecrets...)
+}
+
+func (r *RemoteDevelopment) WithCustomResource(resource *unstructured.Unstructured) *RemoteDevelopment {
+	return r.WithResource(resource)
+}
+
+// WithCustomResourceName selects a custom workload given as "<kind>/<name>", e.g. "rollout/api"
+func (r *RemoteDevelopment) WithCustomResourceName(resourceName string) *RemoteDevelopment {
+	resource, err := workload.GetCustomResource(r.getContext(), r.kubernetesClient, r.customKinds, r.namespace.GetName(), resourceName)
+	if err != nil {
+		panic(err)
+	}
+
+	return r.WithWorkload(resource)
+}
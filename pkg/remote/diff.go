@@ -0,0 +1,77 @@
+package remote
+
+import (
+	"fmt"
+
+	"bunnyshell.com/dev/pkg/k8s/workload"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+)
+
+// Diff shows the unified difference between the rollback snapshot and the live workload,
+// leaving out the remote-dev metadata of the session
+func (r *RemoteDevelopment) Diff() error {
+	ctx := r.getContext()
+
+	if r.clone {
+		if err := r.useClone(ctx); err != nil {
+			return err
+		}
+	}
+
+	resource, err := r.getLiveResource(ctx)
+	if err != nil {
+		return err
+	}
+
+	snapshot, ok := resource.GetAnnotations()[MetadataRollback]
+	if !ok {
+		return ErrNoRollbackManifest
+	}
+
+	liveSnapshot, err := resource.GetSnapshot()
+	if err != nil {
+		return err
+	}
+
+	snapshotYAML, err := getDiffYAML(snapshot)
+	if err != nil {
+		return err
+	}
+
+	liveYAML, err := getDiffYAML(liveSnapshot)
+	if err != nil {
+		return err
+	}
+
+	name := workload.GetLabel(resource) + "-" + resource.GetName()
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(snapshotYAML),
+		B:        difflib.SplitLines(liveYAML),
+		FromFile: name + ".snapshot.yaml",
+		ToFile:   name + ".live.yaml",
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(diff)
+
+	return nil
+}
+
+func getDiffYAML(manifest string) (string, error) {
+	stripped, err := stripSessionMetadata([]byte(manifest))
+	if err != nil {
+		return "", err
+	}
+
+	data, err := yaml.JSONToYAML([]byte(stripped))
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,504 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"bunnyshell.com/dev/pkg/build"
+	"bunnyshell.com/dev/pkg/config"
+	"bunnyshell.com/dev/pkg/k8s"
+	k8sTools "bunnyshell.com/dev/pkg/k8s/tools"
+	"bunnyshell.com/dev/pkg/k8s/workload"
+	bunnyshellSSH "bunnyshell.com/dev/pkg/ssh"
+
+	authorizationV1 "k8s.io/api/authorization/v1"
+)
+
+const (
+	DefaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	BetaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+
+	mutagenDisableAutostartEnv = "MUTAGEN_DISABLE_AUTOSTART"
+)
+
+var ErrDoctorFailed = fmt.Errorf("some checks failed")
+
+type DoctorStatus string
+
+const (
+	DoctorPass DoctorStatus = "PASS"
+	DoctorWarn DoctorStatus = "WARN"
+	DoctorFail DoctorStatus = "FAIL"
+)
+
+// accessRule is an API call made by remote-dev sessions, checked with a SelfSubjectAccessReview
+type accessRule struct {
+	group       string
+	resource    string
+	subresource string
+	verbs       []string
+
+	// namespace defaults to the namespace of the session
+	namespace string
+	name      string
+}
+
+var workloadAccessResources = map[ResourceType]accessRule{
+	Deployment:  {group: "apps", resource: "deployments"},
+	StatefulSet: {group: "apps", resource: "statefulsets"},
+	DaemonSet:   {group: "apps", resource: "daemonsets"},
+	Job:         {group: "batch", resource: "jobs"},
+	CronJob:     {group: "batch", resource: "cronjobs"},
+}
+
+var sessionAccessRules = []accessRule{
+	{resource: "secrets", verbs: []string{"get", "create", "patch", "delete"}},
+	{resource: "persistentvolumeclaims", verbs: []string{"get", "create", "patch", "delete"}},
+	{resource: "pods", verbs: []string{"get", "list", "watch"}},
+	{resource: "pods", subresource: "portforward", verbs: []string{"create"}},
+}
+
+// Doctor checks the local environment and the cluster access needed by remote-dev sessions
+type Doctor struct {
+	kubeConfigOptions *k8s.KubeConfigOptions
+	kubernetesClient  *k8s.KubernetesClient
+
+	namespace    string
+	resourceType ResourceType
+	resourceName string
+	resource     workload.Workload
+	customKinds  []config.Workload
+
+	clone       bool
+	pin         bool
+	pauseGitOps bool
+
+	ctx    context.Context
+	failed bool
+}
+
+func NewDoctor(kubeConfigOptions *k8s.KubeConfigOptions) *Doctor {
+	return &Doctor{
+		kubeConfigOptions: kubeConfigOptions,
+
+		resourceType: Deployment,
+	}
+}
+
+func (d *Doctor) WithContext(ctx context.Context) *Doctor {
+	d.ctx = ctx
+	return d
+}
+
+func (d *Doctor) getContext() context.Context {
+	if d.ctx == nil {
+		return context.Background()
+	}
+
+	return d.ctx
+}
+
+// WithResource checks the access to the named resource instead of any resource of the type
+func (d *Doctor) WithResource(resourceType ResourceType, name string) *Doctor {
+	d.resourceType = resourceType
+	d.resourceName = name
+	return d
+}
+
+// WithCustomResourceName checks a custom workload given as "<kind>/<name>", e.g. "rollout/api"
+func (d *Doctor) WithCustomResourceName(resourceName string) *Doctor {
+	return d.WithResource(CustomResource, resourceName)
+}
+
+func (d *Doctor) WithConfig(config *config.Config) *Doctor {
+	d.customKinds = config.GetWorkloads()
+	return d
+}
+
+func (d *Doctor) WithClone(clone bool) *Doctor {
+	d.clone = clone
+	return d
+}
+
+func (d *Doctor) WithPin(pin bool) *Doctor {
+	d.pin = pin
+	return d
+}
+
+func (d *Doctor) WithPauseGitOps(pauseGitOps bool) *Doctor {
+	d.pauseGitOps = pauseGitOps
+	return d
+}
+
+// Run prints the result and the remedy of every check, failing when any check failed
+func (d *Doctor) Run() error {
+	if d.checkKubeConfig() {
+		d.checkResource()
+		d.checkAccess()
+		d.checkStorageClass()
+		d.checkPodSecurity()
+	}
+
+	d.checkMutagen()
+	d.checkSSHConfig()
+
+	if d.failed {
+		return ErrDoctorFailed
+	}
+
+	return nil
+}
+
+func (d *Doctor) report(status DoctorStatus, name, message, remedy string) {
+	if status == DoctorFail {
+		d.failed = true
+	}
+
+	fmt.Printf("[%s] %s: %s\n", status, name, message)
+	if remedy != "" && status != DoctorPass {
+		fmt.Printf("       %s\n", remedy)
+	}
+}
+
+func (d *Doctor) checkKubeConfig() bool {
+	rawConfig, err := d.kubeConfigOptions.GetClientConfig().RawConfig()
+	if err != nil {
+		d.report(DoctorFail, "kubeconfig", err.Error(), "Check the KUBECONFIG files or the --kubeconfig flag.")
+		return false
+	}
+
+	contextName := d.kubeConfigOptions.Context
+	if contextName == "" {
+		contextName = rawConfig.CurrentContext
+	}
+	if _, ok := rawConfig.Contexts[contextName]; !ok {
+		d.report(DoctorFail, "kubeconfig", fmt.Sprintf("context \"%s\" not found", contextName), "Select a context with --context or \"kubectl config use-context\".")
+		return false
+	}
+
+	kubernetesClient, err := k8s.NewKubernetesClientFromOptions(d.kubeConfigOptions)
+	if err != nil {
+		d.report(DoctorFail, "kubeconfig", err.Error(), "Check the credentials of the context.")
+		return false
+	}
+	d.kubernetesClient = kubernetesClient
+
+	serverVersion, err := kubernetesClient.GetServerVersion()
+	if err != nil {
+		d.report(DoctorFail, "kubeconfig", fmt.Sprintf("cannot reach the cluster of context \"%s\": %s", contextName, err), "Check the network access and the credentials of the context.")
+		return false
+	}
+
+	d.namespace = d.kubeConfigOptions.Namespace
+	if d.namespace == "" {
+		d.namespace, err = kubernetesClient.GetKubeConfigNamespace()
+		if err != nil {
+			d.report(DoctorFail, "kubeconfig", err.Error(), "Select a namespace with --namespace.")
+			return false
+		}
+	}
+
+	d.report(DoctorPass, "kubeconfig", fmt.Sprintf("context \"%s\", server %s, namespace \"%s\"", contextName, serverVersion, d.namespace), "")
+	return true
+}
+
+// checkResource reads the selected resource, whose pod template and GitOps owners are checked too
+func (d *Doctor) checkResource() {
+	if d.resourceName == "" {
+		return
+	}
+
+	var (
+		resource workload.Workload
+		err      error
+	)
+	if d.resourceType == CustomResource {
+		resource, err = workload.GetCustomResource(d.getContext(), d.kubernetesClient, d.customKinds, d.namespace, d.resourceName)
+	} else {
+		resource, err = workload.Get(d.getContext(), d.kubernetesClient, d.resourceType, d.namespace, d.resourceName)
+	}
+	if err != nil {
+		d.report(DoctorFail, "resource", err.Error(), "Check the name of the resource and the namespace.")
+		return
+	}
+
+	d.resource = resource
+	d.report(DoctorPass, "resource", fmt.Sprintf("%s \"%s\"", workload.GetLabel(resource), resource.GetName()), "")
+}
+
+func (d *Doctor) getWorkloadAccessRule() (accessRule, error) {
+	if d.resourceType != CustomResource {
+		rule := workloadAccessResources[d.resourceType]
+		rule.name = d.resourceName
+
+		return rule, nil
+	}
+
+	kindName, name, _ := strings.Cut(d.resourceName, "/")
+	kind, err := workload.LookupKind(d.customKinds, kindName)
+	if err != nil {
+		return accessRule{}, err
+	}
+
+	return accessRule{group: kind.Group, resource: kind.Resource, name: name}, nil
+}
+
+func (d *Doctor) getAccessRules() ([]accessRule, error) {
+	workloadRule, err := d.getWorkloadAccessRule()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []accessRule{
+		{group: workloadRule.group, resource: workloadRule.resource, name: workloadRule.name, verbs: []string{"get", "list", "watch", "patch"}},
+	}
+
+	switch {
+	case d.resourceType == Job || d.resourceType == CronJob:
+		// the session runs in a dev Job
+		rules = append(rules, accessRule{group: "batch", resource: "jobs", verbs: []string{"create", "delete"}})
+	case d.clone || (d.pin && d.resourceType == DaemonSet):
+		// the session runs in a clone, scheduled on the selected node alone for a pinned DaemonSet
+		rules = append(rules, accessRule{group: workloadRule.group, resource: workloadRule.resource, verbs: []string{"create", "delete"}})
+	}
+
+	if d.pin && d.resourceType == StatefulSet {
+		// the pod of the pinned ordinal is replaced on down, when the StatefulSet updates on delete
+		rules = append(rules, accessRule{resource: "pods", verbs: []string{"delete"}})
+	}
+
+	if d.resourceType != DaemonSet && d.resourceType != Job && d.resourceType != CronJob {
+		// the autoscalers of the workload are pinned to the single remote-dev pod
+		rules = append(rules, accessRule{group: "autoscaling", resource: "horizontalpodautoscalers", verbs: []string{"list", "patch"}})
+	}
+
+	rules = append(rules, sessionAccessRules...)
+	if d.pauseGitOps {
+		rules = append(rules, d.getGitOpsAccessRules()...)
+	}
+
+	return rules, nil
+}
+
+// getGitOpsAccessRules checks the GitOps owners of the selected resource,
+// or the usual namespaces of the Argo CD Applications and the Flux objects
+func (d *Doctor) getGitOpsAccessRules() []accessRule {
+	owners := []GitOpsOwner{
+		{Controller: ArgoCD, Namespace: ArgoCDDefaultNamespace},
+		{Controller: FluxKustomization},
+		{Controller: FluxHelmRelease},
+	}
+	if d.resource != nil {
+		owners = getGitOpsOwners(d.resource)
+	}
+
+	rules := []accessRule{}
+	for _, owner := range owners {
+		rule := accessRule{namespace: owner.Namespace, name: owner.Name, verbs: []string{"get", "patch"}}
+		switch owner.Controller {
+		case ArgoCD:
+			rule.group, rule.resource = ArgoCDGroup, ArgoCDApplicationResource
+		case FluxKustomization:
+			rule.group, rule.resource = FluxKustomizeGroup, FluxKustomizationResource
+		case FluxHelmRelease:
+			rule.group, rule.resource = FluxHelmGroup, FluxHelmReleaseResource
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+func (d *Doctor) checkAccess() {
+	rules, err := d.getAccessRules()
+	if err != nil {
+		d.report(DoctorFail, "rbac", err.Error(), "Map the kind in the workloads of the configuration.")
+		return
+	}
+
+	for _, rule := range rules {
+		resource := rule.resource
+		if rule.group != "" {
+			resource += "." + rule.group
+		}
+		if rule.subresource != "" {
+			resource += "/" + rule.subresource
+		}
+
+		namespace := rule.namespace
+		if namespace == "" {
+			namespace = d.namespace
+		}
+
+		denied := []string{}
+		for _, verb := range rule.verbs {
+			status, err := d.kubernetesClient.CanI(d.getContext(), &authorizationV1.ResourceAttributes{
+				Namespace:   namespace,
+				Verb:        verb,
+				Group:       rule.group,
+				Resource:    rule.resource,
+				Subresource: rule.subresource,
+				Name:        rule.name,
+			})
+			if err != nil {
+				d.report(DoctorFail, "rbac", fmt.Sprintf("cannot review access to %s: %s", resource, err), "")
+				return
+			}
+
+			if !status.Allowed {
+				denied = append(denied, verb)
+			}
+		}
+
+		if len(denied) > 0 {
+			d.report(
+				DoctorFail,
+				"rbac",
+				fmt.Sprintf("%s: %s denied", resource, strings.Join(denied, ", ")),
+				fmt.Sprintf("Ask a cluster admin to grant %s on %s in namespace \"%s\".", strings.Join(denied, ", "), resource, namespace),
+			)
+			continue
+		}
+
+		d.report(DoctorPass, "rbac", fmt.Sprintf("%s: %s", resource, strings.Join(rule.verbs, ", ")), "")
+	}
+}
+
+func (d *Doctor) checkStorageClass() {
+	storageClasses, err := d.kubernetesClient.ListStorageClasses(d.getContext())
+	if err != nil {
+		d.report(DoctorWarn, "storage", fmt.Sprintf("cannot list StorageClasses: %s", err), "The remote-dev PVC needs a default StorageClass.")
+		return
+	}
+
+	for _, storageClass := range storageClasses.Items {
+		annotations := storageClass.GetAnnotations()
+		if annotations[DefaultStorageClassAnnotation] == "true" || annotations[BetaDefaultStorageClassAnnotation] == "true" {
+			d.report(DoctorPass, "storage", fmt.Sprintf("default StorageClass \"%s\"", storageClass.GetName()), "")
+			return
+		}
+	}
+
+	d.report(
+		DoctorFail,
+		"storage",
+		"no default StorageClass, the remote-dev PVC would stay Pending",
+		fmt.Sprintf("Mark a StorageClass as default with the %s=true annotation.", DefaultStorageClassAnnotation),
+	)
+}
+
+// checkPodSecurity fails when the enforced level rejects the session pods, which keep the pod template of the
+// resource while the injected remote-dev containers follow the level
+func (d *Doctor) checkPodSecurity() {
+	namespace, err := d.kubernetesClient.GetNamespace(d.getContext(), d.namespace)
+	if err != nil {
+		d.report(DoctorFail, "pod security", err.Error(), "")
+		return
+	}
+
+	level, ok := namespace.GetLabels()[PodSecurityEnforceLabel]
+	if !ok {
+		d.report(DoctorPass, "pod security", "not enforced", "")
+		return
+	}
+
+	if level != PodSecurityRestricted && level != PodSecurityBaseline {
+		d.report(DoctorPass, "pod security", fmt.Sprintf("%s level enforced", level), "")
+		return
+	}
+
+	if d.resource == nil {
+		d.report(DoctorPass, "pod security", fmt.Sprintf("%s level enforced, the remote-dev containers comply, select a resource to check its pod template", level), "")
+		return
+	}
+
+	podSpec, err := d.resource.GetPodSpec()
+	if err != nil {
+		d.report(DoctorFail, "pod security", err.Error(), "")
+		return
+	}
+
+	violations := k8sTools.GetPodSecurityViolations(level, podSpec)
+	if len(violations) > 0 {
+		d.report(
+			DoctorFail,
+			"pod security",
+			fmt.Sprintf("%s level enforced, the session pods of %s \"%s\" would be rejected: %s", level, workload.GetLabel(d.resource), d.resource.GetName(), strings.Join(violations, ", ")),
+			fmt.Sprintf("Fix the pod template, or ask a cluster admin to relax the %s label of namespace \"%s\".", PodSecurityEnforceLabel, d.namespace),
+		)
+		return
+	}
+
+	d.report(DoctorPass, "pod security", fmt.Sprintf("%s level enforced, the pod template of %s \"%s\" complies", level, workload.GetLabel(d.resource), d.resource.GetName()), "")
+}
+
+func (d *Doctor) checkMutagen() {
+	mutagenBinPath, err := getMutagenBinPath()
+	if err != nil {
+		d.report(DoctorFail, "mutagen", err.Error(), "")
+		return
+	}
+
+	if _, err := os.Stat(mutagenBinPath); errors.Is(err, os.ErrNotExist) {
+		d.report(DoctorWarn, "mutagen", fmt.Sprintf("%s not found", mutagenBinPath), "It is downloaded by the next \"remote up\".")
+		return
+	}
+
+	output, err := exec.Command(mutagenBinPath, "version").Output()
+	if err != nil {
+		d.report(DoctorFail, "mutagen", fmt.Sprintf("cannot run %s: %s", mutagenBinPath, err), fmt.Sprintf("Remove %s, it is downloaded again by the next \"remote up\".", mutagenBinPath))
+		return
+	}
+
+	version := strings.TrimSpace(string(output))
+	if version != strings.TrimPrefix(build.MutagenVersion, "v") {
+		d.report(
+			DoctorFail,
+			"mutagen",
+			fmt.Sprintf("version %s, expected %s", version, build.MutagenVersion),
+			fmt.Sprintf("Remove %s, the expected version is downloaded by the next \"remote up\".", mutagenBinPath),
+		)
+		return
+	}
+
+	// listing the sessions fails when a daemon of another version is running, the autostart is disabled
+	// so a stopped daemon is left alone
+	command := exec.Command(mutagenBinPath, "sync", "list")
+	command.Env = append(os.Environ(), mutagenDisableAutostartEnv+"=1")
+	output, err = command.CombinedOutput()
+	if err != nil {
+		message, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+		if strings.Contains(message, "connect to daemon") {
+			d.report(DoctorPass, "mutagen", fmt.Sprintf("version %s, daemon not running", version), "")
+			return
+		}
+		if message == "" {
+			message = err.Error()
+		}
+
+		d.report(DoctorFail, "mutagen", fmt.Sprintf("daemon: %s", message), fmt.Sprintf("Stop the running daemon with \"%s daemon stop\".", mutagenBinPath))
+		return
+	}
+
+	d.report(DoctorPass, "mutagen", fmt.Sprintf("version %s, daemon compatible", version), "")
+}
+
+func (d *Doctor) checkSSHConfig() {
+	included, err := bunnyshellSSH.IsBunnyshellConfigIncluded()
+	if err != nil {
+		d.report(DoctorFail, "ssh config", err.Error(), "")
+		return
+	}
+
+	if !included {
+		d.report(DoctorWarn, "ssh config", "~/.ssh/config doesn't include the remote-dev hosts", "The Include line is added by the next \"remote up\".")
+		return
+	}
+
+	d.report(DoctorPass, "ssh config", "~/.ssh/config includes the remote-dev hosts", "")
+}
@@ -0,0 +1,315 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"bunnyshell.com/dev/pkg/k8s/workload"
+	"bunnyshell.com/dev/pkg/util"
+
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// +enum
+type RestoreStrategy string
+
+const (
+	// RestoreStrategyAuto restores the snapshot when the workload was not changed upstream
+	// and asks the user what to do otherwise
+	RestoreStrategyAuto RestoreStrategy = "auto"
+	// RestoreStrategySnapshot restores the manifest taken at session start
+	RestoreStrategySnapshot RestoreStrategy = "snapshot"
+	// RestoreStrategyUpstream restores the live manifest without the remote-dev changes
+	RestoreStrategyUpstream RestoreStrategy = "upstream"
+)
+
+var ErrNoSessionPatch = fmt.Errorf("no session patch available")
+
+// recordSessionState stores the generation of the patched workload and the patch
+// applied by remote-dev, so upstream changes can be detected and kept on Down
+func (r *RemoteDevelopment) recordSessionState(ctx context.Context, rollbackSnapshot string) error {
+	resource, err := r.getLiveResource(ctx)
+	if err != nil {
+		return err
+	}
+
+	annotations := map[string]string{
+		MetadataGeneration: strconv.FormatInt(resource.GetGeneration(), 10),
+	}
+
+	// custom resources have no strategic merge schema, upstream changes are only detected for them
+	sessionPatch, err := r.getSessionPatch(resource, rollbackSnapshot)
+	if err == nil {
+		annotations[MetadataSessionPatch] = sessionPatch
+	} else if !errors.Is(err, workload.ErrNoSchema) {
+		return err
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// metadata changes do not bump the generation
+	r.sessionGeneration = resource.GetGeneration()
+
+	return resource.Patch(ctx, data)
+}
+
+func (r *RemoteDevelopment) getSessionPatch(resource workload.Workload, rollbackSnapshot string) (string, error) {
+	schema, err := resource.GetSchema()
+	if err != nil {
+		return "", err
+	}
+
+	patchedSnapshot, err := resource.GetSnapshot()
+	if err != nil {
+		return "", err
+	}
+
+	// the remote-dev metadata, e.g. the rollback manifest, is not part of the session changes
+	original, err := stripSessionMetadata([]byte(rollbackSnapshot))
+	if err != nil {
+		return "", err
+	}
+
+	patched, err := stripSessionMetadata([]byte(patchedSnapshot))
+	if err != nil {
+		return "", err
+	}
+
+	sessionPatch, err := strategicpatch.CreateTwoWayMergePatch([]byte(original), []byte(patched), schema)
+	if err != nil {
+		return "", err
+	}
+
+	return string(sessionPatch), nil
+}
+
+func (r *RemoteDevelopment) getSessionGeneration(resource Resource) int64 {
+	// the session workload of a pinned DaemonSet is the node clone
+	if _, ok := resource.GetAnnotations()[MetadataPinnedNode]; ok {
+		if r.pinnedNodeGeneration != 0 {
+			return r.pinnedNodeGeneration
+		}
+	} else if r.sessionGeneration != 0 {
+		return r.sessionGeneration
+	}
+
+	generation, err := strconv.ParseInt(resource.GetAnnotations()[MetadataGeneration], 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return generation
+}
+
+// hasUpstreamChanges reports whether the workload spec was updated by someone else since the session started
+func (r *RemoteDevelopment) hasUpstreamChanges(resource Resource) bool {
+	sessionGeneration := r.getSessionGeneration(resource)
+	if sessionGeneration == 0 {
+		return false
+	}
+
+	return resource.GetGeneration() > sessionGeneration
+}
+
+func (r *RemoteDevelopment) getRestoreManifest(resource workload.Workload, snapshot string) (string, error) {
+	if !r.hasUpstreamChanges(resource) {
+		return snapshot, nil
+	}
+
+	restoreStrategy := r.restoreStrategy
+	if restoreStrategy == "" || restoreStrategy == RestoreStrategyAuto {
+		if _, ok := resource.GetAnnotations()[MetadataSessionPatch]; !ok {
+			fmt.Printf(
+				"WARNING: %s \"%s\" was updated during the session, the upstream changes cannot be kept and the snapshot is restored.\n",
+				workload.GetLabel(resource),
+				resource.GetName(),
+			)
+
+			return snapshot, nil
+		}
+
+		selectedStrategy, err := r.selectRestoreStrategy(resource)
+		if err != nil {
+			return "", err
+		}
+
+		restoreStrategy = selectedStrategy
+	}
+
+	if restoreStrategy == RestoreStrategySnapshot {
+		return snapshot, nil
+	}
+
+	return r.getUpstreamManifest(resource, snapshot)
+}
+
+func (r *RemoteDevelopment) selectRestoreStrategy(resource workload.Workload) (RestoreStrategy, error) {
+	upstreamLabel := "latest upstream without remote-dev changes"
+	snapshotLabel := "snapshot taken at session start"
+
+	answer, err := util.Select(
+		fmt.Sprintf("%s \"%s\" was updated during the session. Restore", workload.GetLabel(resource), resource.GetName()),
+		[]string{upstreamLabel, snapshotLabel},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if answer == snapshotLabel {
+		return RestoreStrategySnapshot, nil
+	}
+
+	return RestoreStrategyUpstream, nil
+}
+
+// getUpstreamManifest replays the upstream changes made during the session on top of the rollback snapshot
+func (r *RemoteDevelopment) getUpstreamManifest(resource workload.Workload, snapshot string) (string, error) {
+	sessionPatch, ok := resource.GetAnnotations()[MetadataSessionPatch]
+	if !ok {
+		return "", ErrNoSessionPatch
+	}
+
+	schema, err := resource.GetSchema()
+	if err != nil {
+		return "", err
+	}
+
+	patchedSnapshot, err := strategicpatch.StrategicMergePatch([]byte(snapshot), []byte(sessionPatch), schema)
+	if err != nil {
+		return "", err
+	}
+
+	liveSnapshot, err := resource.GetSnapshot()
+	if err != nil {
+		return "", err
+	}
+
+	upstreamPatch, err := strategicpatch.CreateTwoWayMergePatch(patchedSnapshot, []byte(liveSnapshot), schema)
+	if err != nil {
+		return "", err
+	}
+
+	manifest, err := strategicpatch.StrategicMergePatch([]byte(snapshot), upstreamPatch, schema)
+	if err != nil {
+		return "", err
+	}
+
+	return stripSessionMetadata(manifest)
+}
+
+func stripSessionMetadata(manifest []byte) (string, error) {
+	object := map[string]any{}
+	if err := json.Unmarshal(manifest, &object); err != nil {
+		return "", err
+	}
+
+	if metadata, ok := object["metadata"].(map[string]any); ok {
+		for _, key := range []string{"annotations", "labels"} {
+			entries, ok := metadata[key].(map[string]any)
+			if !ok {
+				continue
+			}
+
+			for name := range entries {
+				if strings.HasPrefix(name, MetadataPrefix) {
+					delete(entries, name)
+				}
+			}
+
+			// the session might have added the map
+			if len(entries) == 0 {
+				delete(metadata, key)
+			}
+		}
+	}
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// startDriftWatch warns when the workload is updated during the session, resuming the watch from the last
+// seen resource version when the server closes it
+func (r *RemoteDevelopment) startDriftWatch(ctx context.Context) error {
+	resource, err := r.getLiveResource(ctx)
+	if err != nil {
+		return err
+	}
+
+	sessionGeneration := r.getSessionGeneration(resource)
+	if sessionGeneration == 0 {
+		return nil
+	}
+
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", resource.GetName()).String()
+	watcher, err := watchtools.NewRetryWatcher(resource.GetResourceVersion(), &cache.ListWatch{
+		WatchFunc: func(listOptions apiMetaV1.ListOptions) (watch.Interface, error) {
+			listOptions.FieldSelector = fieldSelector
+			return resource.Watch(ctx, listOptions)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	r.driftWatcher = watcher
+	r.completeStep("drift watch", func(context.Context) error {
+		r.stopDriftWatch()
+		return nil
+	})
+
+	go func() {
+		for event := range watcher.ResultChan() {
+			if event.Type != watch.Modified {
+				continue
+			}
+
+			object, ok := event.Object.(Resource)
+			if !ok || object.GetGeneration() <= sessionGeneration {
+				continue
+			}
+
+			sessionGeneration = object.GetGeneration()
+			fmt.Printf(
+				"\nWARNING: %s \"%s\" was updated outside of remote-dev, the dev pod might be replaced.\nRun \"down\" to restore the latest upstream version.\n",
+				workload.GetLabel(resource),
+				object.GetName(),
+			)
+		}
+	}()
+
+	return nil
+}
+
+func (r *RemoteDevelopment) stopDriftWatch() {
+	if r.driftWatcher != nil {
+		r.driftWatcher.Stop()
+	}
+}
+
+func (r *RemoteDevelopment) getLiveResource(ctx context.Context) (workload.Workload, error) {
+	resource, err := r.getResource()
+	if err != nil {
+		return nil, err
+	}
+
+	return resource.Get(ctx)
+}
@@ -0,0 +1,27 @@
+package remote
+
+import (
+	"testing"
+)
+
+func TestStripSessionMetadata(t *testing.T) {
+	tests := []struct {
+		manifest string
+		expected string
+	}{
+		{`{"spec":{"replicas":1}}`, `{"spec":{"replicas":1}}`},
+		{`{"metadata":{"annotations":{"` + MetadataRollback + `":"{}","team":"web"}}}`, `{"metadata":{"annotations":{"team":"web"}}}`},
+		{`{"metadata":{"labels":{"` + MetadataActive + `":"true"}},"spec":{}}`, `{"metadata":{},"spec":{}}`},
+	}
+
+	for _, test := range tests {
+		actual, err := stripSessionMetadata([]byte(test.manifest))
+		if err != nil {
+			t.Fatalf("%s: %s", test.manifest, err)
+		}
+
+		if actual != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.manifest, test.expected, actual)
+		}
+	}
+}
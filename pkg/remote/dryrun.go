@@ -0,0 +1,237 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/k8s/workload"
+
+	applyCoreV1 "k8s.io/client-go/applyconfigurations/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// +enum
+type DryRun string
+
+const (
+	DryRunNone DryRun = "none"
+	// DryRunClient prints the changes without sending them
+	DryRunClient DryRun = "client"
+	// DryRunServer prints the changes and sends them with dryRun=All, so the API server validates them
+	DryRunServer DryRun = "server"
+)
+
+// dryRunAuthorizedKeys stands for the public key generated by Up
+const dryRunAuthorizedKeys = "<generated ssh public key>\n"
+
+var ErrDryRunNotSupported = fmt.Errorf("dry run not supported")
+
+func (r *RemoteDevelopment) WithDryRun(dryRun DryRun) *RemoteDevelopment {
+	r.dryRun = dryRun
+	return r
+}
+
+func (r *RemoteDevelopment) checkDryRunSupported() error {
+	if r.clone {
+		return fmt.Errorf("%w for clones", ErrDryRunNotSupported)
+	}
+
+	if r.isJobResource() {
+		return fmt.Errorf("%w for %s resources", ErrDryRunNotSupported, r.resourceType)
+	}
+
+	if r.isPinnedNode() {
+		return fmt.Errorf("%w for pinned %s resources", ErrDryRunNotSupported, r.resourceType)
+	}
+
+	return r.checkPin()
+}
+
+// DryRunUp prints the Secret, the PVC and the workload changes made by Up as YAML;
+// autoscaler pinning and GitOps pausing are left out
+func (r *RemoteDevelopment) DryRunUp() error {
+	ctx := r.getContext()
+
+	if err := r.checkDryRunSupported(); err != nil {
+		return err
+	}
+
+	r.kubernetesClient.WithDryRun(r.dryRun == DryRunServer)
+
+	secret, err := r.getDryRunSecretApplyConfiguration()
+	if err != nil {
+		return err
+	}
+
+	pvc, err := r.getPVCApplyConfiguration()
+	if err != nil {
+		return err
+	}
+
+	resource, err := r.getResource()
+	if err != nil {
+		return err
+	}
+
+	annotations, _, err := r.getSessionAnnotations(resource)
+	if err != nil {
+		return err
+	}
+
+	data, err := r.getResourceApplyConfiguration(resource, annotations)
+	if err != nil {
+		return err
+	}
+
+	takeOverPatch, err := resource.GetTakeOverPatch(ctx, r.container.Name, resetContainerFields, data)
+	if err != nil {
+		return err
+	}
+
+	if err := printYAMLDocument(fmt.Sprintf("Secret %s: apply", *secret.Name), secret); err != nil {
+		return err
+	}
+
+	if err := printYAMLDocument(fmt.Sprintf("PersistentVolumeClaim %s: apply", *pvc.Name), pvc); err != nil {
+		return err
+	}
+
+	if takeOverPatch != nil {
+		title := fmt.Sprintf("%s %s: container fields take over", resource.GetKind(), resource.GetName())
+		if err := printYAMLDocument(title, json.RawMessage(takeOverPatch)); err != nil {
+			return err
+		}
+	}
+
+	title := fmt.Sprintf("%s %s: apply", resource.GetKind(), resource.GetName())
+	if err := printYAMLDocument(title, json.RawMessage(data)); err != nil {
+		return err
+	}
+
+	if r.dryRun != DryRunServer {
+		return nil
+	}
+
+	if err := r.kubernetesClient.ApplySecret(ctx, secret); err != nil {
+		return err
+	}
+
+	if err := r.kubernetesClient.ApplyPVC(ctx, pvc); err != nil {
+		return err
+	}
+
+	return r.applyResource(ctx, resource, data)
+}
+
+// getDryRunSecretApplyConfiguration uses the existing SSH keys, the missing ones are only generated by Up
+func (r *RemoteDevelopment) getDryRunSecretApplyConfiguration() (*applyCoreV1.SecretApplyConfiguration, error) {
+	found, err := r.lookupSSHKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	if found {
+		return r.getSecretApplyConfiguration()
+	}
+
+	return r.newSecretApplyConfiguration([]byte(dryRunAuthorizedKeys))
+}
+
+// DryRunDown prints the patch restoring the workload as YAML
+func (r *RemoteDevelopment) DryRunDown() error {
+	ctx := r.getContext()
+
+	if err := r.checkDryRunSupported(); err != nil {
+		return err
+	}
+
+	r.kubernetesClient.WithDryRun(r.dryRun == DryRunServer)
+
+	resource, err := r.getResource()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := resource.GetAnnotations()[MetadataPinnedNode]; ok {
+		return fmt.Errorf("%w for pinned %s resources", ErrDryRunNotSupported, r.resourceType)
+	}
+
+	manifest, err := r.getSessionRestoreManifest(resource)
+	if err != nil {
+		return err
+	}
+
+	restorePatch, err := resource.GetRestorePatch(ctx, manifest)
+	if err != nil {
+		return err
+	}
+
+	title := fmt.Sprintf("%s %s: restore", resource.GetKind(), resource.GetName())
+	if err := printYAMLDocument(title, json.RawMessage(restorePatch)); err != nil {
+		return err
+	}
+
+	if resource.GetResourceType() != workload.CustomResource {
+		releaseConfiguration, err := workload.GetReleaseConfiguration(resource)
+		if err != nil {
+			return err
+		}
+
+		title = fmt.Sprintf("%s %s: apply as %s, releasing its fields", resource.GetKind(), resource.GetName(), k8s.BunnyshellRemoteDevFieldManager)
+		if err := printYAMLDocument(title, json.RawMessage(releaseConfiguration)); err != nil {
+			return err
+		}
+	}
+
+	// the controller rolls the pinned pod back, unless the StatefulSet updates on delete
+	replacePinnedPod := false
+	ordinal, isPinnedOrdinal := resource.GetAnnotations()[MetadataPinnedOrdinal]
+	if isPinnedOrdinal {
+		replacePinnedPod, err = isOnDeleteManifest(manifest)
+		if err != nil {
+			return err
+		}
+	}
+	if replacePinnedPod {
+		fmt.Printf("---\n# Pod %s: delete\n", getPinnedPodName(resource, ordinal))
+	}
+
+	pvcName, err := r.getPVCName()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("---\n# PersistentVolumeClaim %s: delete\n", pvcName)
+
+	if r.dryRun != DryRunServer {
+		return nil
+	}
+
+	if err := resource.Restore(ctx, manifest); err != nil {
+		return err
+	}
+
+	if replacePinnedPod {
+		if err := r.replacePinnedPod(ctx, resource, ordinal); err != nil {
+			return err
+		}
+	}
+
+	return r.deletePVC(ctx)
+}
+
+func printYAMLDocument(title string, object any) error {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+
+	document, err := yaml.JSONToYAML(data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("---\n# %s\n%s", title, document)
+
+	return nil
+}
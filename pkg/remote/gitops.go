@@ -0,0 +1,284 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// +enum
+type GitOpsController string
+
+const (
+	ArgoCD            GitOpsController = "argocd"
+	FluxKustomization GitOpsController = "flux-kustomization"
+	FluxHelmRelease   GitOpsController = "flux-helmrelease"
+
+	ArgoCDTrackingIdAnnotation = "argocd.argoproj.io/tracking-id"
+	ArgoCDInstanceLabel        = "argocd.argoproj.io/instance"
+	ArgoCDDefaultNamespace     = "argocd"
+	ArgoCDGroup                = "argoproj.io"
+	ArgoCDApplicationResource  = "applications"
+
+	FluxKustomizationNameLabel      = "kustomize.toolkit.fluxcd.io/name"
+	FluxKustomizationNamespaceLabel = "kustomize.toolkit.fluxcd.io/namespace"
+	FluxReconcileAnnotation         = "kustomize.toolkit.fluxcd.io/reconcile"
+	FluxReconcileDisabled           = "disabled"
+	FluxKustomizeGroup              = "kustomize.toolkit.fluxcd.io"
+	FluxKustomizationResource       = "kustomizations"
+
+	FluxHelmReleaseNameLabel      = "helm.toolkit.fluxcd.io/name"
+	FluxHelmReleaseNamespaceLabel = "helm.toolkit.fluxcd.io/namespace"
+	FluxHelmGroup                 = "helm.toolkit.fluxcd.io"
+	FluxHelmReleaseResource       = "helmreleases"
+)
+
+// GitOpsOwner is a GitOps object reconciling the workload, along with the
+// settings changed to pause it so they can be put back on Down
+type GitOpsOwner struct {
+	Controller GitOpsController `json:"controller"`
+	Namespace  string           `json:"namespace"`
+	Name       string           `json:"name"`
+
+	Previous json.RawMessage `json:"previous,omitempty"`
+}
+
+func (o GitOpsOwner) String() string {
+	switch o.Controller {
+	case ArgoCD:
+		return fmt.Sprintf("Argo CD Application %s/%s", o.Namespace, o.Name)
+	case FluxKustomization:
+		return fmt.Sprintf("Flux Kustomization %s/%s", o.Namespace, o.Name)
+	case FluxHelmRelease:
+		return fmt.Sprintf("Flux HelmRelease %s/%s", o.Namespace, o.Name)
+	default:
+		return fmt.Sprintf("%s %s/%s", o.Controller, o.Namespace, o.Name)
+	}
+}
+
+func getGitOpsOwners(resource Resource) []GitOpsOwner {
+	owners := []GitOpsOwner{}
+
+	labels := resource.GetLabels()
+	annotations := resource.GetAnnotations()
+
+	if trackingId, ok := annotations[ArgoCDTrackingIdAnnotation]; ok {
+		owners = append(owners, newArgoCDOwner(strings.SplitN(trackingId, ":", 2)[0]))
+	} else if instance, ok := labels[ArgoCDInstanceLabel]; ok {
+		owners = append(owners, newArgoCDOwner(instance))
+	}
+
+	if name, ok := labels[FluxKustomizationNameLabel]; ok {
+		owners = append(owners, GitOpsOwner{
+			Controller: FluxKustomization,
+			Namespace:  labels[FluxKustomizationNamespaceLabel],
+			Name:       name,
+		})
+	}
+
+	if name, ok := labels[FluxHelmReleaseNameLabel]; ok {
+		owners = append(owners, GitOpsOwner{
+			Controller: FluxHelmRelease,
+			Namespace:  labels[FluxHelmReleaseNamespaceLabel],
+			Name:       name,
+		})
+	}
+
+	return owners
+}
+
+// newArgoCDOwner handles "<namespace>_<name>" application names used for apps in any namespace
+func newArgoCDOwner(applicationName string) GitOpsOwner {
+	namespace := ArgoCDDefaultNamespace
+	if parts := strings.SplitN(applicationName, "_", 2); len(parts) == 2 {
+		namespace, applicationName = parts[0], parts[1]
+	}
+
+	return GitOpsOwner{
+		Controller: ArgoCD,
+		Namespace:  namespace,
+		Name:       applicationName,
+	}
+}
+
+// checkGitOpsOwners warns about controllers that would revert the patch, and pauses them when requested
+func (r *RemoteDevelopment) checkGitOpsOwners(ctx context.Context, resource Resource, annotations map[string]string) error {
+	owners := getGitOpsOwners(resource)
+	if len(owners) == 0 {
+		return nil
+	}
+
+	if _, ok := resource.GetAnnotations()[MetadataGitOps]; ok {
+		// paused by a previous session, the rollback restores the workload without the record of the owners
+		r.completeStep("gitops", func(ctx context.Context) error {
+			return r.resumeGitOpsOwners(ctx, resource)
+		})
+
+		return nil
+	}
+
+	r.StopSpinner()
+	defer r.StartSpinner("")
+
+	if !r.pauseGitOps {
+		for _, owner := range owners {
+			fmt.Printf("WARNING: %s is managed by %s and might be reverted during the session.\n", resource.GetName(), owner)
+		}
+		fmt.Print("Use --pause-gitops to pause reconciliation while the session is active.\n")
+
+		return nil
+	}
+
+	for i := range owners {
+		owner := &owners[i]
+		if err := r.pauseGitOpsOwner(ctx, owner, annotations); err != nil {
+			return fmt.Errorf("cannot pause %s: %w", owner, err)
+		}
+		// the owners are only recorded on the workload by the apply, which might fail
+		r.completeStep("pause "+owner.String(), func(ctx context.Context) error {
+			return r.resumeGitOpsOwner(ctx, *owner)
+		})
+
+		fmt.Printf("Paused %s reconciliation for the session\n", owner)
+	}
+
+	data, err := json.Marshal(owners)
+	if err != nil {
+		return err
+	}
+	annotations[MetadataGitOps] = string(data)
+
+	return nil
+}
+
+func (r *RemoteDevelopment) pauseGitOpsOwner(ctx context.Context, owner *GitOpsOwner, annotations map[string]string) error {
+	switch owner.Controller {
+	case ArgoCD:
+		application, err := r.getGitOpsObject(ctx, ArgoCDGroup, ArgoCDApplicationResource, owner)
+		if err != nil {
+			return err
+		}
+
+		automated, found, err := unstructured.NestedFieldNoCopy(application.Object, "spec", "syncPolicy", "automated")
+		if err != nil || !found {
+			return err
+		}
+
+		owner.Previous, err = json.Marshal(automated)
+		if err != nil {
+			return err
+		}
+
+		return r.patchGitOpsObject(ctx, ArgoCDGroup, ArgoCDApplicationResource, owner, map[string]any{
+			"spec": map[string]any{
+				"syncPolicy": map[string]any{
+					"automated": nil,
+				},
+			},
+		})
+	case FluxKustomization:
+		// the annotation is dropped together with the rest of the session changes on restore
+		annotations[FluxReconcileAnnotation] = FluxReconcileDisabled
+
+		return nil
+	case FluxHelmRelease:
+		helmRelease, err := r.getGitOpsObject(ctx, FluxHelmGroup, FluxHelmReleaseResource, owner)
+		if err != nil {
+			return err
+		}
+
+		suspend, _, err := unstructured.NestedBool(helmRelease.Object, "spec", "suspend")
+		if err != nil {
+			return err
+		}
+
+		owner.Previous, err = json.Marshal(suspend)
+		if err != nil {
+			return err
+		}
+
+		return r.patchGitOpsObject(ctx, FluxHelmGroup, FluxHelmReleaseResource, owner, map[string]any{
+			"spec": map[string]any{
+				"suspend": true,
+			},
+		})
+	default:
+		return fmt.Errorf("gitops controller \"%s\" not supported", owner.Controller)
+	}
+}
+
+func (r *RemoteDevelopment) resumeGitOpsOwners(ctx context.Context, resource Resource) error {
+	pausedOwners, ok := resource.GetAnnotations()[MetadataGitOps]
+	if !ok {
+		return nil
+	}
+
+	owners := []GitOpsOwner{}
+	if err := json.Unmarshal([]byte(pausedOwners), &owners); err != nil {
+		return err
+	}
+
+	for _, owner := range owners {
+		if err := r.resumeGitOpsOwner(ctx, owner); err != nil {
+			return fmt.Errorf("cannot resume %s: %w", owner, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *RemoteDevelopment) resumeGitOpsOwner(ctx context.Context, owner GitOpsOwner) error {
+	if len(owner.Previous) == 0 {
+		return nil
+	}
+
+	var previous any
+	if err := json.Unmarshal(owner.Previous, &previous); err != nil {
+		return err
+	}
+
+	switch owner.Controller {
+	case ArgoCD:
+		return r.patchGitOpsObject(ctx, ArgoCDGroup, ArgoCDApplicationResource, &owner, map[string]any{
+			"spec": map[string]any{
+				"syncPolicy": map[string]any{
+					"automated": previous,
+				},
+			},
+		})
+	case FluxHelmRelease:
+		return r.patchGitOpsObject(ctx, FluxHelmGroup, FluxHelmReleaseResource, &owner, map[string]any{
+			"spec": map[string]any{
+				"suspend": previous,
+			},
+		})
+	default:
+		return nil
+	}
+}
+
+func (r *RemoteDevelopment) getGitOpsObject(ctx context.Context, group, resource string, owner *GitOpsOwner) (*unstructured.Unstructured, error) {
+	gvr, err := r.kubernetesClient.GetPreferredGroupVersionResource(group, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.kubernetesClient.GetUnstructured(ctx, gvr, owner.Namespace, owner.Name)
+}
+
+func (r *RemoteDevelopment) patchGitOpsObject(ctx context.Context, group, resource string, owner *GitOpsOwner, patch map[string]any) error {
+	gvr, err := r.kubernetesClient.GetPreferredGroupVersionResource(group, resource)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+
+	return r.kubernetesClient.MergePatchUnstructured(ctx, gvr, owner.Namespace, owner.Name, data)
+}
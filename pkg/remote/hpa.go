@@ -0,0 +1,115 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"bunnyshell.com/dev/pkg/k8s/workload"
+)
+
+// PinnedAutoscaler holds the original replica bounds of a HorizontalPodAutoscaler
+// targeting the workload, restored on Down
+type PinnedAutoscaler struct {
+	Name        string `json:"name"`
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	MaxReplicas int32  `json:"maxReplicas"`
+}
+
+// pinAutoscalers keeps HPAs from scaling the workload past the single remote-dev pod,
+// or from scaling the replicas of a pinned StatefulSet ordinal
+func (r *RemoteDevelopment) pinAutoscalers(ctx context.Context, resource workload.Workload, annotations map[string]string) error {
+	if r.resourceType == DaemonSet {
+		return nil
+	}
+
+	if _, ok := resource.GetAnnotations()[MetadataAutoscalers]; ok {
+		// pinned by a previous session, the rollback restores the workload without the record of the autoscalers
+		r.completeStep("autoscalers", func(ctx context.Context) error {
+			return r.restoreAutoscalers(ctx, resource)
+		})
+
+		return nil
+	}
+
+	autoscalers, err := r.kubernetesClient.ListHorizontalPodAutoscalers(ctx, resource.GetNamespace())
+	if err != nil {
+		return err
+	}
+
+	kind := resource.GetKind()
+	replicas := r.getPinnedReplicas(resource)
+	pinned := []PinnedAutoscaler{}
+	for _, autoscaler := range autoscalers.Items {
+		targetRef := autoscaler.Spec.ScaleTargetRef
+		if targetRef.Kind != kind || targetRef.Name != resource.GetName() {
+			continue
+		}
+
+		if err := r.patchAutoscalerReplicas(ctx, resource.GetNamespace(), autoscaler.GetName(), int32Ptr(replicas), replicas); err != nil {
+			return fmt.Errorf("cannot pin HorizontalPodAutoscaler %s: %w", autoscaler.GetName(), err)
+		}
+
+		pinnedAutoscaler := PinnedAutoscaler{
+			Name:        autoscaler.GetName(),
+			MinReplicas: autoscaler.Spec.MinReplicas,
+			MaxReplicas: autoscaler.Spec.MaxReplicas,
+		}
+		// the bounds are only recorded on the workload by the apply, which might fail
+		r.completeStep("pin HorizontalPodAutoscaler "+pinnedAutoscaler.Name, func(ctx context.Context) error {
+			return r.patchAutoscalerReplicas(ctx, resource.GetNamespace(), pinnedAutoscaler.Name, pinnedAutoscaler.MinReplicas, pinnedAutoscaler.MaxReplicas)
+		})
+
+		pinned = append(pinned, pinnedAutoscaler)
+	}
+
+	if len(pinned) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(pinned)
+	if err != nil {
+		return err
+	}
+	annotations[MetadataAutoscalers] = string(data)
+
+	return nil
+}
+
+func (r *RemoteDevelopment) restoreAutoscalers(ctx context.Context, resource Resource) error {
+	pinnedAutoscalers, ok := resource.GetAnnotations()[MetadataAutoscalers]
+	if !ok {
+		return nil
+	}
+
+	pinned := []PinnedAutoscaler{}
+	if err := json.Unmarshal([]byte(pinnedAutoscalers), &pinned); err != nil {
+		return err
+	}
+
+	for _, autoscaler := range pinned {
+		if err := r.patchAutoscalerReplicas(ctx, resource.GetNamespace(), autoscaler.Name, autoscaler.MinReplicas, autoscaler.MaxReplicas); err != nil {
+			return fmt.Errorf("cannot restore HorizontalPodAutoscaler %s: %w", autoscaler.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *RemoteDevelopment) patchAutoscalerReplicas(ctx context.Context, namespace, name string, minReplicas *int32, maxReplicas int32) error {
+	data, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"minReplicas": minReplicas,
+			"maxReplicas": maxReplicas,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.kubernetesClient.PatchHorizontalPodAutoscaler(ctx, namespace, name, data)
+}
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
@@ -0,0 +1,135 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"bunnyshell.com/dev/pkg/build"
+	k8sTools "bunnyshell.com/dev/pkg/k8s/tools"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	applyCoreV1 "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+var imageDigestExp = regexp.MustCompile("^sha256:[a-f0-9]{64}$")
+
+var ErrInvalidImageDigest = fmt.Errorf("invalid image digest, expected sha256:<64 hex characters>")
+
+// WithDevImage runs the dev container with another image, e.g. with a toolchain; the seed copy of the
+// sync path still comes from the workload image
+func (r *RemoteDevelopment) WithDevImage(devImage string) *RemoteDevelopment {
+	r.devImage = devImage
+	return r
+}
+
+// getSeedImage returns the workload image seeding the sync path, read from the rollback snapshot when
+// the session already replaced it with the dev image
+func (r *RemoteDevelopment) getSeedImage() (string, error) {
+	resource, err := r.getResource()
+	if err != nil {
+		return "", err
+	}
+
+	snapshot, ok := resource.GetAnnotations()[MetadataRollback]
+	if !ok {
+		return r.container.Image, nil
+	}
+
+	manifest := map[string]any{}
+	if err := json.Unmarshal([]byte(snapshot), &manifest); err != nil {
+		return "", err
+	}
+
+	podTemplatePath := strings.ReplaceAll(strings.TrimPrefix(resource.GetPodTemplatePath(), "/"), "/", ".")
+	container, err := k8sTools.GetUnstructuredContainerByName(&unstructured.Unstructured{Object: manifest}, podTemplatePath, r.container.Name)
+	if err != nil {
+		return "", err
+	}
+
+	return container.Image, nil
+}
+
+// WithImageRegistry pulls the helper images from a mirror, keeping their repository path
+func (r *RemoteDevelopment) WithImageRegistry(imageRegistry string) *RemoteDevelopment {
+	r.imageRegistry = strings.TrimRight(imageRegistry, "/")
+	return r
+}
+
+// WithBinariesImage replaces the remote-binaries image with a full reference
+func (r *RemoteDevelopment) WithBinariesImage(binariesImage string) *RemoteDevelopment {
+	r.binariesImage = binariesImage
+	return r
+}
+
+// WithBinariesImageDigest pins the remote-binaries image, pulled from the mirror when set
+func (r *RemoteDevelopment) WithBinariesImageDigest(digest string) *RemoteDevelopment {
+	r.binariesImageDigest = digest
+	return r
+}
+
+// ValidateImageDigest checks a digest given to WithBinariesImageDigest, an empty digest is valid
+func ValidateImageDigest(digest string) error {
+	if digest != "" && !imageDigestExp.MatchString(digest) {
+		return fmt.Errorf("%w: %s", ErrInvalidImageDigest, digest)
+	}
+
+	return nil
+}
+
+func (r *RemoteDevelopment) WithImagePullSecrets(imagePullSecrets ...string) *RemoteDevelopment {
+	r.imagePullSecrets = imagePullSecrets
+	return r
+}
+
+func (r *RemoteDevelopment) getSSHServerImage() string {
+	if r.binariesImage != "" {
+		return r.binariesImage
+	}
+
+	// public.ecr.aws/x0p9x6p7/bunnyshell/remote-binaries -> <registry>/x0p9x6p7/bunnyshell/remote-binaries
+	repository := k8sTools.GetMirrorImage(build.SSHServerImage, r.imageRegistry)
+
+	if r.binariesImageDigest != "" {
+		return fmt.Sprintf("%s@%s", repository, r.binariesImageDigest)
+	}
+
+	return fmt.Sprintf("%s:%s", repository, build.SSHServerVersion)
+}
+
+// prepareImagePullSecrets adds the configured secrets to the ones of the pod, since the list is replaced as a whole
+func (r *RemoteDevelopment) prepareImagePullSecrets(podSpec *applyCoreV1.PodSpecApplyConfiguration) error {
+	if len(r.imagePullSecrets) == 0 {
+		return nil
+	}
+
+	resource, err := r.getResource()
+	if err != nil {
+		return err
+	}
+
+	resourcePodSpec, err := resource.GetPodSpec()
+	if err != nil {
+		return err
+	}
+
+	names := []string{}
+	for _, imagePullSecret := range resourcePodSpec.ImagePullSecrets {
+		names = append(names, imagePullSecret.Name)
+	}
+	for _, name := range r.imagePullSecrets {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	imagePullSecrets := []*applyCoreV1.LocalObjectReferenceApplyConfiguration{}
+	for _, name := range names {
+		imagePullSecrets = append(imagePullSecrets, applyCoreV1.LocalObjectReference().WithName(name))
+	}
+	podSpec.WithImagePullSecrets(imagePullSecrets...)
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package remote
+
+import (
+	"errors"
+	"testing"
+
+	"bunnyshell.com/dev/pkg/build"
+	"bunnyshell.com/dev/pkg/k8s/workload"
+
+	appsV1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	applyCoreV1 "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+func TestGetSSHServerImage(t *testing.T) {
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	r := &RemoteDevelopment{}
+	if actual := r.getSSHServerImage(); actual != build.SSHServerImage+":"+build.SSHServerVersion {
+		t.Errorf("unexpected default image %s", actual)
+	}
+
+	r.WithImageRegistry("mirror.local/").WithBinariesImageDigest(digest)
+	if expected, actual := "mirror.local/x0p9x6p7/bunnyshell/remote-binaries@"+digest, r.getSSHServerImage(); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	r.WithBinariesImage("mirror.local/remote-binaries:custom")
+	if expected, actual := "mirror.local/remote-binaries:custom", r.getSSHServerImage(); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+
+	if err := ValidateImageDigest("sha256:0123"); !errors.Is(err, ErrInvalidImageDigest) {
+		t.Errorf("expected %s, got %v", ErrInvalidImageDigest, err)
+	}
+}
+
+func TestPrepareImagePullSecrets(t *testing.T) {
+	deployment := &appsV1.Deployment{}
+	deployment.Spec.Template.Spec.ImagePullSecrets = []coreV1.LocalObjectReference{{Name: "registry"}, {Name: "mirror"}}
+
+	r := &RemoteDevelopment{workload: workload.NewDeployment(nil, deployment)}
+	r.WithImagePullSecrets("mirror", "cache")
+
+	podSpec := applyCoreV1.PodSpec()
+	if err := r.prepareImagePullSecrets(podSpec); err != nil {
+		t.Fatal(err)
+	}
+
+	actual := []string{}
+	for _, imagePullSecret := range podSpec.ImagePullSecrets {
+		actual = append(actual, *imagePullSecret.Name)
+	}
+	if len(actual) != 3 || actual[0] != "registry" || actual[1] != "mirror" || actual[2] != "cache" {
+		t.Errorf("expected [registry mirror cache], got %v", actual)
+	}
+}
+
+func TestHasBinariesShell(t *testing.T) {
+	r := &RemoteDevelopment{}
+	if !r.hasBinariesShell() {
+		t.Error("expected the static shell of the released image")
+	}
+
+	// a mirror or a digest may serve an image older than the static shell
+	if r.WithImageRegistry("mirror.local"); r.hasBinariesShell() {
+		t.Error("expected no static shell for a mirror")
+	}
+}
@@ -1,19 +1,21 @@
 package remote
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 
+	"bunnyshell.com/dev/pkg/k8s/workload"
 	mutagenConfig "bunnyshell.com/dev/pkg/mutagen/config"
-	coreV1 "k8s.io/api/core/v1"
 
 	"bunnyshell.com/dev/pkg/util"
 )
 
 var (
-	ErrNoNamespaces = fmt.Errorf("no namespaces available")
+	ErrNoNamespaces = workload.ErrNoNamespaces
 
-	ErrNoResources = fmt.Errorf("no resources available")
+	ErrNoResources = workload.ErrNoResources
 
 	ErrNoDeployments  = fmt.Errorf("no deployments available")
 	ErrNoStatefulSets = fmt.Errorf("no statefulsets available")
@@ -22,267 +24,122 @@ var (
 	ErrNoNamespaceSelected = fmt.Errorf("no namespace selected")
 	ErrNoResourceSelected  = fmt.Errorf("no resource selected")
 
-	ErrContainerNotFound = fmt.Errorf("container not found")
+	ErrContainerNotFound = workload.ErrContainerNotFound
 )
 
 func (r *RemoteDevelopment) SelectNamespace() error {
-	namespaces, err := r.kubernetesClient.ListNamespaces()
+	namespace, err := workload.SelectNamespace(r.getContext(), r.kubernetesClient, r.AutoSelectSingleResource)
 	if err != nil {
 		return err
 	}
 
-	if len(namespaces.Items) == 0 {
-		return ErrNoNamespaces
-	}
-
-	if len(namespaces.Items) == 1 && r.AutoSelectSingleResource {
-		r.namespace = namespaces.Items[0].DeepCopy()
-		return nil
-	}
-
-	items := []string{}
-	for _, item := range namespaces.Items {
-		items = append(items, item.GetName())
-	}
-
-	namespace, err := util.Select("Select namespace", items)
-	if err != nil {
-		return err
-	}
-
-	for _, item := range namespaces.Items {
-		if item.GetName() != namespace {
-			continue
-		}
-
-		r.WithNamespace(item.DeepCopy())
-		return nil
-	}
-
+	r.WithNamespace(namespace)
 	return nil
 }
 
 func (r *RemoteDevelopment) SelectResource() error {
-	availableResources, err := r.getAvailableResourceFromNamespace(r.namespace.GetName())
-	if err != nil {
-		return err
-	}
-
-	if len(availableResources) == 0 {
-		return ErrNoResources
-	}
-
-	if len(availableResources) == 1 && r.AutoSelectSingleResource {
-		r.WithResource(availableResources[0])
-
-		return nil
-	}
-
-	selectItems := []string{}
-	resourcesItemsMap := map[string]Resource{}
-	for _, resourceItem := range availableResources {
-		resourceType, err := r.getResourceType(resourceItem)
-		if err != nil {
-			return err
-		}
-
-		resourceItemLabel := fmt.Sprintf("%s / %s", resourceType, resourceItem.GetName())
-		selectItems = append(selectItems, resourceItemLabel)
-		resourcesItemsMap[resourceItemLabel] = resourceItem
-	}
-
-	selectedResourceItemLabel, err := util.Select("Select resource", selectItems)
-	if err != nil {
-		return err
-	}
-
-	r.WithResource(resourcesItemsMap[selectedResourceItemLabel])
-	return nil
+	return r.selectWorkload(r.getContext(), ErrNoResources, Deployment, StatefulSet, DaemonSet, Job, CronJob, CustomResource)
 }
 
-func (r *RemoteDevelopment) getAvailableResourceFromNamespace(namespace string) ([]Resource, error) {
-	availableResources := []Resource{}
-
-	deployments, err := r.kubernetesClient.ListDeployments(namespace)
-	if err != nil {
-		return nil, err
-	}
-	for _, deploymentItem := range deployments.Items {
-		item := deploymentItem
-		availableResources = append(availableResources, &item)
-	}
-
-	statefulsets, err := r.kubernetesClient.ListStatefulSets(namespace)
-	if err != nil {
-		return nil, err
-	}
-	for _, statefulsetItem := range statefulsets.Items {
-		item := statefulsetItem
-		availableResources = append(availableResources, &item)
-	}
+func (r *RemoteDevelopment) SelectDeployment() error {
+	return r.selectWorkload(r.getContext(), ErrNoDeployments, Deployment)
+}
 
-	daemonsets, err := r.kubernetesClient.ListDaemonSets(namespace)
-	if err != nil {
-		return nil, err
-	}
-	for _, daemonsetItem := range daemonsets.Items {
-		item := daemonsetItem
-		availableResources = append(availableResources, &item)
-	}
+func (r *RemoteDevelopment) SelectStatefulSet() error {
+	return r.selectWorkload(r.getContext(), ErrNoStatefulSets, StatefulSet)
+}
 
-	return availableResources, nil
+func (r *RemoteDevelopment) SelectDaemonSet() error {
+	return r.selectWorkload(r.getContext(), ErrNoDaemonSets, DaemonSet)
 }
 
-func (r *RemoteDevelopment) SelectDeployment() error {
+func (r *RemoteDevelopment) selectWorkload(ctx context.Context, errNoResources error, resourceTypes ...ResourceType) error {
 	if r.namespace == nil {
 		return ErrNoNamespaceSelected
 	}
 
-	deployments, err := r.kubernetesClient.ListDeployments(r.namespace.GetName())
+	available, err := workload.List(ctx, r.kubernetesClient, r.namespace.GetName(), r.customKinds, resourceTypes...)
 	if err != nil {
 		return err
 	}
 
-	if len(deployments.Items) == 0 {
-		return ErrNoDeployments
-	}
-
-	if len(deployments.Items) == 1 && r.AutoSelectSingleResource {
-		r.WithDeployment(deployments.Items[0].DeepCopy())
-
-		return nil
+	workloads := []workload.Workload{}
+	for _, item := range available {
+		if !isDevJob(item) {
+			workloads = append(workloads, item)
+		}
 	}
 
-	items := []string{}
-	for _, item := range deployments.Items {
-		items = append(items, item.GetName())
+	if len(workloads) == 0 {
+		return errNoResources
 	}
 
-	deployment, err := util.Select("Select deployment", items)
+	selected, err := workload.Select(workloads, r.AutoSelectSingleResource)
 	if err != nil {
 		return err
 	}
 
-	for _, item := range deployments.Items {
-		if item.GetName() != deployment {
-			continue
-		}
-
-		r.WithDeployment(item.DeepCopy())
-		return nil
-	}
-
+	r.WithWorkload(selected)
 	return nil
 }
 
-func (r *RemoteDevelopment) SelectStatefulSet() error {
-	if r.namespace == nil {
-		return ErrNoNamespaceSelected
-	}
-
-	statefulSets, err := r.kubernetesClient.ListStatefulSets(r.namespace.GetName())
+func (r *RemoteDevelopment) SelectContainer() error {
+	containers, err := r.getResourceContainers()
 	if err != nil {
 		return err
 	}
 
-	if len(statefulSets.Items) == 0 {
-		return ErrNoStatefulSets
-	}
-
-	if len(statefulSets.Items) == 1 && r.AutoSelectSingleResource {
-		r.WithStatefulSet(statefulSets.Items[0].DeepCopy())
+	if r.ContainerName != "" {
+		container, _, err := workload.FindContainer(containers, nil, r.ContainerName)
+		if err != nil {
+			return err
+		}
 
+		r.WithContainer(container.DeepCopy())
 		return nil
 	}
 
-	items := []string{}
-	for _, item := range statefulSets.Items {
-		items = append(items, item.GetName())
-	}
-
-	statefulSet, err := util.Select("Select statefulset", items)
+	container, _, err := workload.SelectContainer(containers, nil, r.AutoSelectSingleResource)
 	if err != nil {
 		return err
 	}
 
-	for _, item := range statefulSets.Items {
-		if item.GetName() != statefulSet {
-			continue
-		}
-
-		r.WithStatefulSet(item.DeepCopy())
-		return nil
-	}
+	r.WithContainer(container.DeepCopy())
 
 	return nil
 }
 
-func (r *RemoteDevelopment) SelectDaemonSet() error {
-	if r.namespace == nil {
-		return ErrNoNamespaceSelected
+// SelectNode picks the node of the DaemonSet pod used for the session, any other kind runs a single pod
+func (r *RemoteDevelopment) SelectNode() error {
+	if r.resourceType != DaemonSet {
+		return nil
 	}
 
-	daemonSets, err := r.kubernetesClient.ListDaemonSets(r.namespace.GetName())
+	resource, err := r.getResource()
 	if err != nil {
 		return err
 	}
 
-	if len(daemonSets.Items) == 0 {
-		return ErrNoDaemonSets
-	}
-
-	if len(daemonSets.Items) == 1 && r.AutoSelectSingleResource {
-		r.WithDaemonSet(daemonSets.Items[0].DeepCopy())
-
-		return nil
-	}
-
-	items := []string{}
-	for _, item := range daemonSets.Items {
-		items = append(items, item.GetName())
-	}
-
-	daemonSet, err := util.Select("Select daemonset", items)
+	resourceSelector, err := resource.GetSelector()
 	if err != nil {
 		return err
 	}
 
-	for _, item := range daemonSets.Items {
-		if item.GetName() != daemonSet {
-			continue
-		}
-
-		r.WithDaemonSet(item.DeepCopy())
-		return nil
-	}
-
-	return nil
-}
-
-func (r *RemoteDevelopment) SelectContainer() error {
-	containers, err := r.getResourceContainers()
+	pods, err := workload.ListPods(r.getContext(), r.kubernetesClient, resource.GetNamespace(), resourceSelector)
 	if err != nil {
 		return err
 	}
 
-	if r.ContainerName != "" {
-		for _, container := range containers {
-			if container.Name == r.ContainerName {
-				r.WithContainer(container.DeepCopy())
-				return nil
-			}
-		}
-
-		return ErrContainerNotFound
+	nodeName, err := workload.SelectNode(pods, r.AutoSelectSingleResource)
+	if errors.Is(err, workload.ErrNoNodes) {
+		// no pod scheduled yet, any node will do
+		return nil
 	}
-
-	container, err := r.selectContainer(containers)
 	if err != nil {
 		return err
 	}
 
-	r.WithContainer(container.DeepCopy())
-
+	r.WithNodeName(nodeName)
 	return nil
 }
 
@@ -314,27 +171,3 @@ func (r *RemoteDevelopment) SelectRemoteSyncPath() error {
 	r.WithRemoteSyncPath(syncPath)
 	return nil
 }
-
-func (r *RemoteDevelopment) selectContainer(containers []coreV1.Container) (*coreV1.Container, error) {
-	if len(containers) == 1 && r.AutoSelectSingleResource {
-		return &containers[0], nil
-	}
-
-	items := []string{}
-	for _, item := range containers {
-		items = append(items, item.Name)
-	}
-
-	container, err := util.Select("Select container", items)
-	if err != nil {
-		return nil, err
-	}
-
-	for _, item := range containers {
-		if item.Name == container {
-			return &item, nil
-		}
-	}
-
-	return nil, ErrContainerNotFound
-}
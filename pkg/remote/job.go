@@ -0,0 +1,334 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"bunnyshell.com/dev/pkg/k8s/workload"
+	"bunnyshell.com/dev/pkg/util"
+
+	batchV1 "k8s.io/api/batch/v1"
+	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	applyCoreV1 "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+const (
+	MetadataDevJobOf            = MetadataPrefix + "dev-job-of"
+	MetadataPreviouslySuspended = MetadataPrefix + "previously-suspended"
+
+	JobNameLabel = "job-name"
+)
+
+// labels set by the job controller, which are rejected when creating a new Job
+var jobControllerLabels = []string{
+	"controller-uid",
+	"job-name",
+	"batch.kubernetes.io/controller-uid",
+	"batch.kubernetes.io/job-name",
+}
+
+func (r *RemoteDevelopment) isJobResource() bool {
+	return r.resourceType == Job || r.resourceType == CronJob
+}
+
+func (r *RemoteDevelopment) getDevJobName(resource Resource) (string, error) {
+	return r.getCloneName(resource)
+}
+
+func (r *RemoteDevelopment) getDevJobSelector() (*apiMetaV1.LabelSelector, error) {
+	resource, err := r.getResource()
+	if err != nil {
+		return nil, err
+	}
+
+	devJobName, err := r.getDevJobName(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiMetaV1.LabelSelector{
+		MatchLabels: map[string]string{JobNameLabel: devJobName},
+	}, nil
+}
+
+// prepareJob marks the selected Job or CronJob, suspending the CronJob, and starts a dev Job
+// running the remote-dev pod, since Job pod templates are immutable
+func (r *RemoteDevelopment) prepareJob(ctx context.Context) error {
+	r.StartSpinner(" Setup k8s job for remote development")
+	defer r.StopSpinner()
+
+	resource, err := r.getResource()
+	if err != nil {
+		return err
+	}
+
+	annotations := make(map[string]string)
+	annotations[MetadataStartedAt] = strconv.FormatInt(r.startedAt, 10)
+	annotations[MetadataContainer] = r.container.Name
+	if err := r.checkGitOpsOwners(ctx, resource, annotations); err != nil {
+		return err
+	}
+
+	labels := make(map[string]string)
+	labels[MetadataActive] = "true"
+
+	resourcePatch := map[string]any{
+		"metadata": map[string]any{
+			"annotations": annotations,
+			"labels":      labels,
+		},
+	}
+
+	if cronJob, ok := resource.GetObject().(*batchV1.CronJob); ok {
+		if _, ok := resource.GetAnnotations()[MetadataPreviouslySuspended]; !ok {
+			annotations[MetadataPreviouslySuspended] = strconv.FormatBool(cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend)
+		}
+
+		resourcePatch["spec"] = map[string]any{
+			"suspend": true,
+		}
+	}
+
+	data, err := json.Marshal(resourcePatch)
+	if err != nil {
+		return err
+	}
+
+	if err := resource.Patch(ctx, data); err != nil {
+		return err
+	}
+	r.completeStep("patch", func(ctx context.Context) error {
+		live, err := resource.Get(ctx)
+		if err != nil {
+			return err
+		}
+
+		return r.releaseJob(ctx, live)
+	})
+
+	return r.startDevJob(ctx, resource)
+}
+
+func (r *RemoteDevelopment) getJobSpec() (*batchV1.JobSpec, error) {
+	resource, err := r.getResource()
+	if err != nil {
+		return nil, err
+	}
+
+	switch object := resource.GetObject().(type) {
+	case *batchV1.Job:
+		return object.Spec.DeepCopy(), nil
+	case *batchV1.CronJob:
+		return object.Spec.JobTemplate.Spec.DeepCopy(), nil
+	default:
+		return nil, r.resourceTypeNotSupportedError()
+	}
+}
+
+func (r *RemoteDevelopment) startDevJob(ctx context.Context, resource Resource) error {
+	jobSpec, err := r.getJobSpec()
+	if err != nil {
+		return err
+	}
+
+	devJobName, err := r.getDevJobName(resource)
+	if err != nil {
+		return err
+	}
+
+	podTemplate, err := r.getDevJobPodTemplate(jobSpec.Template)
+	if err != nil {
+		return err
+	}
+
+	// a single pod kept running for the whole session, with a selector generated for the new Job
+	jobSpec.Template = *podTemplate
+	jobSpec.Selector = nil
+	jobSpec.ManualSelector = nil
+	jobSpec.Parallelism = int32Ptr(1)
+	jobSpec.Completions = int32Ptr(1)
+	jobSpec.ActiveDeadlineSeconds = nil
+	jobSpec.Suspend = nil
+
+	devJob := &batchV1.Job{
+		ObjectMeta: apiMetaV1.ObjectMeta{
+			Name:      devJobName,
+			Namespace: resource.GetNamespace(),
+			Labels: map[string]string{
+				MetadataActive:  "true",
+				MetadataService: resource.GetName(),
+			},
+			Annotations: map[string]string{
+				MetadataDevJobOf: resource.GetName(),
+			},
+		},
+		Spec: *jobSpec,
+	}
+
+	// a dev Job left from a previous session runs an outdated pod spec
+	if err := r.deleteDevJob(ctx, resource); err != nil {
+		return err
+	}
+
+	_, err = r.kubernetesClient.CreateJob(ctx, resource.GetNamespace(), devJob)
+	return err
+}
+
+// getDevJobPodTemplate applies the remote-dev pod template on top of the original one
+func (r *RemoteDevelopment) getDevJobPodTemplate(podTemplate coreV1.PodTemplateSpec) (*coreV1.PodTemplateSpec, error) {
+	for i := range podTemplate.Spec.Containers {
+		container := &podTemplate.Spec.Containers[i]
+		if container.Name != r.container.Name {
+			continue
+		}
+
+		container.Args = nil
+		container.Env = nil
+		container.ReadinessProbe = nil
+		container.LivenessProbe = nil
+		container.StartupProbe = nil
+	}
+
+	for _, label := range jobControllerLabels {
+		delete(podTemplate.Labels, label)
+	}
+
+	podTemplatePatch := applyCoreV1.PodTemplateSpec()
+	if err := r.preparePodTemplateSpec(podTemplatePatch); err != nil {
+		return nil, err
+	}
+
+	original, err := json.Marshal(podTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := json.Marshal(podTemplatePatch)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := strategicpatch.StrategicMergePatch(original, patch, coreV1.PodTemplateSpec{})
+	if err != nil {
+		return nil, err
+	}
+
+	devPodTemplate := &coreV1.PodTemplateSpec{}
+	if err := json.Unmarshal(data, devPodTemplate); err != nil {
+		return nil, err
+	}
+
+	return devPodTemplate, nil
+}
+
+func (r *RemoteDevelopment) deleteDevJob(ctx context.Context, resource Resource) error {
+	devJobName, err := r.getDevJobName(resource)
+	if err != nil {
+		return err
+	}
+
+	err = r.kubernetesClient.DeleteJob(ctx, resource.GetNamespace(), devJobName)
+	if apiErrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return r.waitDevJobDeleted(ctx, resource.GetNamespace(), devJobName)
+}
+
+func (r *RemoteDevelopment) waitDevJobDeleted(ctx context.Context, namespace, devJobName string) error {
+	startTimestamp := time.Now().Unix()
+	for {
+		_, err := r.kubernetesClient.GetJob(ctx, namespace, devJobName)
+		if apiErrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		nowTimestamp := time.Now().Unix()
+		if nowTimestamp-startTimestamp >= r.waitTimeout {
+			break
+		}
+
+		if err := util.Sleep(ctx, 1*time.Second); err != nil {
+			return err
+		}
+	}
+
+	// timeout reached
+	return fmt.Errorf("dev job \"%s\" not deleted", devJobName)
+}
+
+// releaseJobResource drops the remote-dev metadata and puts back the CronJob schedule
+func (r *RemoteDevelopment) releaseJobResource(ctx context.Context, resource workload.Workload) error {
+	annotations := make(map[string]any)
+	for key := range resource.GetAnnotations() {
+		if strings.HasPrefix(key, MetadataPrefix) {
+			annotations[key] = nil
+		}
+	}
+
+	if hasPausedFluxKustomization(resource) {
+		annotations[FluxReconcileAnnotation] = nil
+	}
+
+	labels := make(map[string]any)
+	for key := range resource.GetLabels() {
+		if strings.HasPrefix(key, MetadataPrefix) {
+			labels[key] = nil
+		}
+	}
+
+	resourcePatch := map[string]any{
+		"metadata": map[string]any{
+			"annotations": annotations,
+			"labels":      labels,
+		},
+	}
+
+	if resource.GetResourceType() == CronJob {
+		previouslySuspended, _ := strconv.ParseBool(resource.GetAnnotations()[MetadataPreviouslySuspended])
+		resourcePatch["spec"] = map[string]any{
+			"suspend": previouslySuspended,
+		}
+	}
+
+	data, err := json.Marshal(resourcePatch)
+	if err != nil {
+		return err
+	}
+
+	return resource.Patch(ctx, data)
+}
+
+func hasPausedFluxKustomization(resource Resource) bool {
+	owners := []GitOpsOwner{}
+	if err := json.Unmarshal([]byte(resource.GetAnnotations()[MetadataGitOps]), &owners); err != nil {
+		return false
+	}
+
+	for _, owner := range owners {
+		if owner.Controller == FluxKustomization {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isDevJob reports whether the resource is a Job started by remote-dev, excluded from the resource selection
+func isDevJob(resource Resource) bool {
+	_, ok := resource.GetAnnotations()[MetadataDevJobOf]
+	return ok
+}
@@ -1,27 +1,25 @@
 package remote
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
-	"time"
 
 	"bunnyshell.com/dev/pkg/build"
-	"bunnyshell.com/dev/pkg/k8s/patch"
+	"bunnyshell.com/dev/pkg/k8s/workload"
 
 	k8sTools "bunnyshell.com/dev/pkg/k8s/tools"
-	appsV1 "k8s.io/api/apps/v1"
 	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
-	appsCoreV1 "k8s.io/client-go/applyconfigurations/apps/v1"
 	applyCoreV1 "k8s.io/client-go/applyconfigurations/core/v1"
-	applyMetaV1 "k8s.io/client-go/applyconfigurations/meta/v1"
 )
 
 const (
@@ -32,8 +30,13 @@ const (
 	MetadataContainer = MetadataPrefix + "container"
 	MetadataRollback  = MetadataPrefix + "rollback-manifest"
 
-	MetadataKubeCTLLastAppliedConf = "kubectl.kubernetes.io/last-applied-configuration"
-	MetadataK8SRevision            = "deployment.kubernetes.io/revision"
+	MetadataGeneration   = MetadataPrefix + "generation"
+	MetadataSessionPatch = MetadataPrefix + "session-patch"
+	MetadataGitOps       = MetadataPrefix + "gitops-paused"
+	MetadataAutoscalers  = MetadataPrefix + "pinned-autoscalers"
+
+	MetadataKubeCTLLastAppliedConf = workload.MetadataKubeCTLLastAppliedConf
+	MetadataK8SRevision            = workload.MetadataK8SRevision
 
 	DebugMetadataActive = "debug.bunnyshell.com/active"
 
@@ -47,325 +50,171 @@ const (
 	SecretAuthorizedKeysKeyName = "authorized_keys"
 	SecretAuthorizedKeysPath    = "ssh/authorized_keys"
 
-	ContainerNameBinaries         = "remote-dev-bin"
-	ContainerNameWorkPermissions  = "remote-dev-work-permissions"
-	ContainerNameWork             = "remote-dev-work"
-	ContainerImageWorkPermissions = "alpine:3.17"
+	ContainerNameBinaries = "remote-dev-bin"
+	ContainerNameWork     = "remote-dev-work"
 
 	motdFileName = "motd.txt"
 
+	// static shell of the remote-binaries image since 0.4.0, so the application image needs no shell, e.g. distroless;
+	// its applets are linked in binariesAppletsDir and found after the tools of the image
+	binariesShell      = "busybox"
+	binariesAppletsDir = "applets"
+
 	// ConfigSourceDir = "config"
 )
 
 var (
-	ErrInvalidResourceType = fmt.Errorf("invalid resource type")
+	ErrInvalidResourceType = workload.ErrInvalidResourceType
+	ErrNoRollbackManifest  = fmt.Errorf("no rollback manifest available")
 )
 
-type Resource interface {
-	GetName() string
-	GetNamespace() string
-	GetAnnotations() map[string]string
-	GetLabels() map[string]string
-}
+type Resource = workload.Resource
 
 func (r *RemoteDevelopment) resourceTypeNotSupportedError() error {
 	return fmt.Errorf("resource type \"%s\" not supported", r.resourceType)
 }
 
-func (r *RemoteDevelopment) getResourcePatch() (patch.Resource, error) {
-	switch r.resourceType {
-	case Deployment:
-		var replicas int32 = 1
-		strategy := appsV1.RecreateDeploymentStrategyType
-		return &patch.DeploymentPatchConfiguration{
-			ObjectMetaApplyConfiguration: &applyMetaV1.ObjectMetaApplyConfiguration{},
-			Spec: &patch.DeploymentSpecPatchConfiguration{
-				Strategy: &patch.DeploymentStrategyPatchConfiguration{
-					Type:          &strategy,
-					RollingUpdate: nil,
-				},
-				Replicas: &replicas,
-			},
-		}, nil
-	case StatefulSet:
-		var replicas int32 = 1
-		strategy := appsV1.OnDeleteStatefulSetStrategyType
-		return &patch.StatefulSetPatchConfiguration{
-			ObjectMetaApplyConfiguration: &applyMetaV1.ObjectMetaApplyConfiguration{},
-			Spec: &patch.StatefulSetSpecPatchConfiguration{
-				UpdateStrategy: &patch.StatefulSetStrategyPatchConfiguration{
-					Type:          &strategy,
-					RollingUpdate: nil,
-				},
-				Replicas: &replicas,
-			},
-		}, nil
-	case DaemonSet:
-		strategy := appsV1.OnDeleteDaemonSetStrategyType
-		return &patch.DaemonSetPatchConfiguration{
-			ObjectMetaApplyConfiguration: &applyMetaV1.ObjectMetaApplyConfiguration{},
-			Spec: &patch.DaemonSetSpecPatchConfiguration{
-				UpdateStrategy: &patch.DaemonSetStrategyPatchConfiguration{
-					Type:          &strategy,
-					RollingUpdate: nil,
-				},
-			},
-		}, nil
-	default:
-		return nil, r.resourceTypeNotSupportedError()
-	}
-}
-
-func (r *RemoteDevelopment) prepareResource() error {
+func (r *RemoteDevelopment) prepareResource(ctx context.Context) error {
 	r.StartSpinner(" Setup k8s pod for remote development")
 	defer r.StopSpinner()
 
-	currentManifestSnapshot, err := r.getCurrentManifestSnapshot()
-	if err != nil {
-		return err
-	}
-
 	resource, err := r.getResource()
 	if err != nil {
 		return err
 	}
 
-	resourcePatch, err := r.getResourcePatch()
+	annotations, rollbackSnapshot, err := r.getSessionAnnotations(resource)
 	if err != nil {
 		return err
 	}
 
-	annotations := make(map[string]string)
-	annotations[MetadataStartedAt] = strconv.FormatInt(r.startedAt, 10)
-	annotations[MetadataContainer] = r.container.Name
-	_, ok := resource.GetAnnotations()[MetadataRollback]
-	if !ok {
-		annotations[MetadataRollback] = string(currentManifestSnapshot)
-	}
-	labels := make(map[string]string)
-	labels[MetadataActive] = "true"
-
-	resourcePatch.WithAnnotations(annotations).WithLabels(labels)
-
-	podTemplateSpec := applyCoreV1.PodTemplateSpec()
-	if err := r.preparePodTemplateSpec(podTemplateSpec); err != nil {
+	if err := r.checkGitOpsOwners(ctx, resource, annotations); err != nil {
 		return err
 	}
-	resourcePatch.WithSpecTemplate(podTemplateSpec)
 
-	data, err := json.Marshal(resourcePatch)
-	if err != nil {
+	if err := r.pinAutoscalers(ctx, resource, annotations); err != nil {
 		return err
 	}
 
-	if err := r.resetResourceContainer(resource); err != nil {
-		return fmt.Errorf("cannot reset container: %w", err)
-	}
+	if r.cloneCreated {
+		// the clone already runs the session pod template, only the session metadata is added
+		if err := r.patchCloneMetadata(ctx, resource, annotations); err != nil {
+			return err
+		}
+	} else {
+		data, err := r.getResourceApplyConfiguration(resource, annotations)
+		if err != nil {
+			return err
+		}
 
-	switch r.resourceType {
-	case Deployment:
-		return r.kubernetesClient.PatchDeployment(resource.GetNamespace(), resource.GetName(), data)
-	case StatefulSet:
-		return r.kubernetesClient.PatchStatefulSet(resource.GetNamespace(), resource.GetName(), data)
-	case DaemonSet:
-		return r.kubernetesClient.PatchDaemonSet(resource.GetNamespace(), resource.GetName(), data)
-	default:
-		return r.resourceTypeNotSupportedError()
-	}
-}
+		if err := r.applyResource(ctx, resource, data); err != nil {
+			return err
+		}
+		r.completeStep("patch", func(ctx context.Context) error {
+			live, err := resource.Get(ctx)
+			if err != nil {
+				return err
+			}
 
-func (r *RemoteDevelopment) resetResourceContainer(resource Resource) error {
-	containerIndex, err := r.getContainerIndex()
-	if err != nil {
-		return err
+			return r.restorePatchedResource(ctx, live)
+		})
 	}
 
-	// we need to use replace because remove fails if the path is missing
-	resetJSON, err := json.Marshal([]map[string]any{
-		{
-			"op":    "replace",
-			"path":  fmt.Sprintf("/spec/template/spec/containers/%d/args", containerIndex),
-			"value": []string{},
-		},
-		{
-			"op":    "replace",
-			"path":  fmt.Sprintf("/spec/template/spec/containers/%d/env", containerIndex),
-			"value": []map[string]string{},
-		},
-		{
-			"op":    "replace",
-			"path":  fmt.Sprintf("/spec/template/spec/containers/%d/readinessProbe", containerIndex),
-			"value": nil,
-		},
-		{
-			"op":    "replace",
-			"path":  fmt.Sprintf("/spec/template/spec/containers/%d/livenessProbe", containerIndex),
-			"value": nil,
-		},
-		{
-			"op":    "replace",
-			"path":  fmt.Sprintf("/spec/template/spec/containers/%d/startupProbe", containerIndex),
-			"value": nil,
-		},
-	})
+	return r.recordSessionState(ctx, rollbackSnapshot)
+}
 
+// getSessionAnnotations returns the annotations marking the session and the rollback snapshot,
+// taken now unless a previous session left one
+func (r *RemoteDevelopment) getSessionAnnotations(resource workload.Workload) (map[string]string, string, error) {
+	currentManifestSnapshot, err := resource.GetSnapshot()
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 
-	switch r.resourceType {
-	case Deployment:
-		return r.kubernetesClient.BatchPatchDeployment(resource.GetNamespace(), resource.GetName(), resetJSON)
-	case StatefulSet:
-		return r.kubernetesClient.BatchPatchStatefulSet(resource.GetNamespace(), resource.GetName(), resetJSON)
-	case DaemonSet:
-		return r.kubernetesClient.BatchPatchDaemonSet(resource.GetNamespace(), resource.GetName(), resetJSON)
-	default:
-		return r.resourceTypeNotSupportedError()
+	annotations := make(map[string]string)
+	annotations[MetadataStartedAt] = strconv.FormatInt(r.startedAt, 10)
+	annotations[MetadataContainer] = r.container.Name
+	rollbackSnapshot, ok := resource.GetAnnotations()[MetadataRollback]
+	if !ok {
+		rollbackSnapshot = currentManifestSnapshot
+		annotations[MetadataRollback] = rollbackSnapshot
 	}
+
+	return annotations, rollbackSnapshot, nil
 }
 
-func (r *RemoteDevelopment) restoreDeployment() error {
-	resource, err := r.getResource()
+func (r *RemoteDevelopment) getResourceApplyConfiguration(resource workload.Workload, annotations map[string]string) ([]byte, error) {
+	resourcePatch, err := resource.GetPatch()
 	if err != nil {
-		return err
-	}
-
-	snapshot, ok := resource.GetAnnotations()[MetadataRollback]
-	if !ok {
-		return fmt.Errorf("no rollback manifest available")
+		return nil, err
 	}
 
-	switch r.resourceType {
-	case Deployment:
-		deployment := &appsV1.Deployment{}
-		if err := json.Unmarshal([]byte(snapshot), deployment); err != nil {
-			return err
-		}
-
-		_, err = r.kubernetesClient.UpdateDeployment(deployment.GetNamespace(), deployment)
-		return err
-	case StatefulSet:
-		statefulSet := &appsV1.StatefulSet{}
-		if err := json.Unmarshal([]byte(snapshot), statefulSet); err != nil {
-			return err
+	if r.isPinnedOrdinal() {
+		if err := r.pinOrdinalPatch(resourcePatch, annotations); err != nil {
+			return nil, err
 		}
+	}
 
-		_, err = r.kubernetesClient.UpdateStatefulSet(statefulSet.GetNamespace(), statefulSet)
-		return err
-	case DaemonSet:
-		daemonSet := &appsV1.DaemonSet{}
-		if err := json.Unmarshal([]byte(snapshot), daemonSet); err != nil {
-			return err
-		}
+	labels := make(map[string]string)
+	labels[MetadataActive] = "true"
 
-		_, err = r.kubernetesClient.UpdateDaemonSet(daemonSet.GetNamespace(), daemonSet)
-		return err
-	default:
-		return r.resourceTypeNotSupportedError()
-	}
-}
+	resourcePatch.WithAnnotations(annotations).WithLabels(labels)
 
-func (r *RemoteDevelopment) getResourceManifest() ([]byte, error) {
-	resource, err := r.getResource()
-	if err != nil {
+	podTemplateSpec := applyCoreV1.PodTemplateSpec()
+	if err := r.preparePodTemplateSpec(podTemplateSpec); err != nil {
 		return nil, err
 	}
+	resourcePatch.WithSpecTemplate(podTemplateSpec)
 
-	return json.Marshal(resource)
+	return json.Marshal(resourcePatch)
 }
 
-func (r *RemoteDevelopment) getCurrentManifestSnapshot() (string, error) {
-	fullSnapshot, err := r.getResourceManifest()
+func (r *RemoteDevelopment) restoreResource(ctx context.Context, resource workload.Workload) error {
+	manifest, err := r.getSessionRestoreManifest(resource)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	var snapshot []byte
-	switch r.resourceType {
-	case Deployment:
-		applyResource := &appsCoreV1.DeploymentApplyConfiguration{}
-		if err := json.Unmarshal(fullSnapshot, applyResource); err != nil {
-			return "", err
-		}
-
-		// strip unnecessary data
-		applyResource.WithStatus(nil)
-		applyResource.Generation = nil
-		applyResource.UID = nil
-		applyResource.ResourceVersion = nil
-		annotations := make(map[string]string)
-		for key, value := range applyResource.Annotations {
-			if key == MetadataK8SRevision || key == MetadataKubeCTLLastAppliedConf {
-				continue
-			}
-
-			annotations[key] = value
-		}
-		applyResource.Annotations = annotations
-
-		snapshot, err = json.Marshal(applyResource)
-		if err != nil {
-			return "", err
-		}
-	case StatefulSet:
-		applyResource := &appsCoreV1.StatefulSetApplyConfiguration{}
-		if err := json.Unmarshal(fullSnapshot, applyResource); err != nil {
-			return "", err
-		}
+	return resource.Restore(ctx, manifest)
+}
 
-		// strip unnecessary data
-		applyResource.WithStatus(nil)
-		applyResource.Generation = nil
-		applyResource.UID = nil
-		applyResource.ResourceVersion = nil
-		annotations := make(map[string]string)
-		for key, value := range applyResource.Annotations {
-			if key == MetadataK8SRevision || key == MetadataKubeCTLLastAppliedConf {
-				continue
-			}
+func (r *RemoteDevelopment) getSessionRestoreManifest(resource workload.Workload) (string, error) {
+	snapshot, ok := resource.GetAnnotations()[MetadataRollback]
+	if !ok {
+		return "", ErrNoRollbackManifest
+	}
 
-			annotations[key] = value
-		}
-		applyResource.Annotations = annotations
+	return r.getRestoreManifest(resource, snapshot)
+}
 
-		snapshot, err = json.Marshal(applyResource)
-		if err != nil {
-			return "", err
-		}
-	case DaemonSet:
-		applyResource := &appsCoreV1.DaemonSetApplyConfiguration{}
-		if err := json.Unmarshal(fullSnapshot, applyResource); err != nil {
-			return "", err
-		}
+func (r *RemoteDevelopment) ensurePVC(ctx context.Context) error {
+	remoteDevPVC, err := r.getPVCApplyConfiguration()
+	if err != nil {
+		return err
+	}
 
-		// strip unnecessary data
-		applyResource.WithStatus(nil)
-		applyResource.Generation = nil
-		applyResource.UID = nil
-		applyResource.ResourceVersion = nil
-		annotations := make(map[string]string)
-		for key, value := range applyResource.Annotations {
-			if key == MetadataK8SRevision || key == MetadataKubeCTLLastAppliedConf {
-				continue
-			}
+	// the volume of a previous session keeps the synced work
+	namespace, name := *remoteDevPVC.Namespace, *remoteDevPVC.Name
+	_, err = r.kubernetesClient.GetPVC(ctx, namespace, name)
+	if err != nil && !apiErrors.IsNotFound(err) {
+		return err
+	}
+	pvcExisted := err == nil
 
-			annotations[key] = value
-		}
-		applyResource.Annotations = annotations
+	if err := r.kubernetesClient.ApplyPVC(ctx, remoteDevPVC); err != nil {
+		return err
+	}
 
-		snapshot, err = json.Marshal(applyResource)
-		if err != nil {
-			return "", err
-		}
-	default:
-		return "", r.resourceTypeNotSupportedError()
+	if !pvcExisted {
+		r.completeStep("pvc", func(ctx context.Context) error {
+			return r.kubernetesClient.DeletePVC(ctx, namespace, name)
+		})
 	}
 
-	return string(snapshot), nil
+	return nil
 }
 
-func (r *RemoteDevelopment) ensurePVC() error {
+func (r *RemoteDevelopment) getPVCApplyConfiguration() (*applyCoreV1.PersistentVolumeClaimApplyConfiguration, error) {
 	labels := make(map[string]string)
 	labels[MetadataActive] = "true"
 
@@ -375,22 +224,20 @@ func (r *RemoteDevelopment) ensurePVC() error {
 
 	resource, err := r.getResource()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	pvcName, err := r.getPVCName()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	remoteDevPVC := applyCoreV1.PersistentVolumeClaim(pvcName, resource.GetNamespace()).
+	return applyCoreV1.PersistentVolumeClaim(pvcName, resource.GetNamespace()).
 		WithLabels(labels).
 		WithSpec(applyCoreV1.PersistentVolumeClaimSpec().
 			WithAccessModes(coreV1.ReadWriteOnce).
 			WithResources(applyCoreV1.VolumeResourceRequirements().
-				WithRequests(resourceLimits)))
-
-	return r.kubernetesClient.ApplyPVC(remoteDevPVC)
+				WithRequests(resourceLimits))), nil
 }
 
 func (r *RemoteDevelopment) preparePodTemplateSpec(podTemplateSpec *applyCoreV1.PodTemplateSpecApplyConfiguration) error {
@@ -415,6 +262,18 @@ func (r *RemoteDevelopment) preparePodTemplateSpec(podTemplateSpec *applyCoreV1.
 
 func (r *RemoteDevelopment) preparePodSpec(podTemplateSpec *applyCoreV1.PodTemplateSpecApplyConfiguration) error {
 	podSpec := applyCoreV1.PodSpec()
+	podSecurityContext, err := r.getPodSecurityContext()
+	if err != nil {
+		return err
+	}
+	if podSecurityContext != nil {
+		podSpec.WithSecurityContext(podSecurityContext)
+	}
+
+	if err := r.prepareImagePullSecrets(podSpec); err != nil {
+		return err
+	}
+
 	if err := r.prepareVolumes(podSpec); err != nil {
 		return err
 	}
@@ -456,6 +315,7 @@ func (r *RemoteDevelopment) prepareVolumes(podSpec *applyCoreV1.PodSpecApplyConf
 		WithPersistentVolumeClaim(applyCoreV1.PersistentVolumeClaimVolumeSource().
 			WithClaimName(pvcName))
 	volumes = append(volumes, workVolume)
+	volumes = append(volumes, r.getWritableVolumes()...)
 
 	podSpec.WithVolumes(volumes...)
 
@@ -463,6 +323,11 @@ func (r *RemoteDevelopment) prepareVolumes(podSpec *applyCoreV1.PodSpecApplyConf
 }
 
 func (r *RemoteDevelopment) prepareInitContainers(podSpec *applyCoreV1.PodSpecApplyConfiguration) error {
+	securityContext, err := r.getInitSecurityContext()
+	if err != nil {
+		return err
+	}
+
 	pullPolicy := coreV1.PullIfNotPresent
 	image := r.getSSHServerImage()
 	if strings.Contains(image, ":latest") {
@@ -470,17 +335,30 @@ func (r *RemoteDevelopment) prepareInitContainers(podSpec *applyCoreV1.PodSpecAp
 	}
 
 	binariesVolumeMountPath := "/remote-dev-bin"
+	linkApplets := ""
+	if r.hasBinariesShell() {
+		// link the applets of the static shell relatively to the mount path
+		linkApplets = fmt.Sprintf(
+			"mkdir %[1]s/%[2]s && for applet in $(%[1]s/%[3]s --list); do ln -s ../%[3]s %[1]s/%[2]s/$applet; done && ",
+			binariesVolumeMountPath,
+			binariesAppletsDir,
+			binariesShell,
+		)
+	}
+
 	binariesInitContainer := applyCoreV1.Container().
 		WithName(ContainerNameBinaries).
 		WithCommand("sh", "-c", fmt.Sprintf(
 			// copy binaries to the volume and create empty motd file with loose permissions
 			// MOTD is written to by start.sh under the user of the image used for the dev container
-			"cp -p /usr/local/bin/* %[1]s && echo > %[1]s/%[2]s && chmod 666 %[1]s/%[2]s",
+			"cp -p /usr/local/bin/* %[1]s && %[3]secho > %[1]s/%[2]s && chmod 666 %[1]s/%[2]s",
 			binariesVolumeMountPath,
 			motdFileName,
+			linkApplets,
 		)).
 		WithImage(image).
 		WithImagePullPolicy(pullPolicy).
+		WithSecurityContext(securityContext).
 		WithVolumeMounts(applyCoreV1.VolumeMount().
 			WithName(VolumeNameBinaries).
 			WithMountPath(binariesVolumeMountPath))
@@ -489,41 +367,43 @@ func (r *RemoteDevelopment) prepareInitContainers(podSpec *applyCoreV1.PodSpecAp
 	appSourceDir := r.getRemoteSyncPathHash()
 	workVolumeAppSourceDir := fmt.Sprintf("%s/%s", workVolumesMountPath, appSourceDir)
 
-	workPermissionsInitContainer := applyCoreV1.Container().
-		WithName(ContainerNameWorkPermissions).
-		WithCommand("sh", "-c", fmt.Sprintf(
-			"chmod 777 %s",
-			workVolumesMountPath,
-		)).
-		WithImage(ContainerImageWorkPermissions).
-		WithImagePullPolicy(coreV1.PullIfNotPresent).
-		WithVolumeMounts(applyCoreV1.VolumeMount().
-			WithName(VolumeNameWork).
-			WithMountPath(workVolumesMountPath))
+	// the seed copy runs in the application image with the static shell
+	seedImage, err := r.getSeedImage()
+	if err != nil {
+		return err
+	}
+
+	shellCommand, toolPrefix := []string{"sh"}, ""
+	if shell := r.getBinariesShell(binariesVolumeMountPath); shell != "" {
+		shellCommand, toolPrefix = []string{shell, "sh"}, shell+" "
+	}
 
 	workInitContainer := applyCoreV1.Container().
 		WithName(ContainerNameWork).
-		WithCommand("sh", "-c", fmt.Sprintf(
-			"[ \"$(ls -A %s)\" ] || (cp -RpT %s %s; exit 0)",
+		WithCommand(append(shellCommand, "-c", fmt.Sprintf(
+			"[ \"$(%[1]sls -A %[2]s)\" ] || (%[1]scp -RpT %[3]s %[2]s; exit 0)",
+			toolPrefix,
 			workVolumeAppSourceDir,
 			r.remoteSyncPath,
-			workVolumeAppSourceDir,
-		)).
-		WithImage(r.container.Image).
+		))...).
+		WithImage(seedImage).
 		WithImagePullPolicy(coreV1.PullIfNotPresent).
-		WithVolumeMounts(applyCoreV1.VolumeMount().
-			WithName(VolumeNameWork).
-			WithMountPath(workVolumesMountPath))
-
-	podSpec.WithInitContainers(binariesInitContainer, workPermissionsInitContainer, workInitContainer)
+		WithSecurityContext(securityContext).
+		WithVolumeMounts(
+			applyCoreV1.VolumeMount().
+				WithName(VolumeNameWork).
+				WithMountPath(workVolumesMountPath),
+			applyCoreV1.VolumeMount().
+				WithName(VolumeNameBinaries).
+				WithMountPath(binariesVolumeMountPath),
+		)
+
+	// the work volume is writable through the fsGroup of the pod
+	podSpec.WithInitContainers(binariesInitContainer, workInitContainer)
 
 	return nil
 }
 
-func (r *RemoteDevelopment) getSSHServerImage() string {
-	return fmt.Sprintf("%s:%s", build.SSHServerImage, build.SSHServerVersion)
-}
-
 func (r *RemoteDevelopment) getRemoteSyncPathHash() string {
 	hash := md5.Sum([]byte(r.remoteSyncPath))
 	return hex.EncodeToString(hash[:])
@@ -557,27 +437,72 @@ func (r *RemoteDevelopment) prepareContainer(podSpec *applyCoreV1.PodSpecApplyCo
 		// 	WithSubPath(ConfigSourceDir),
 	}
 
-	nullProbe := r.getNullProbeApplyConfiguration()
+	shell := r.getBinariesShell(binariesVolumeMountPath)
+	nullProbe := r.getNullProbeApplyConfiguration(shell)
+
+	startCommand := []string{binariesVolumeMountPath + "/start.sh"}
+	if shell != "" {
+		// start.sh runs with the static shell, the arguments of the container are passed on
+		startCommand = []string{shell, "sh", "-c", fmt.Sprintf(
+			"PATH=\"${PATH:+$PATH:}%s/%s\" exec %s sh %s/start.sh \"$@\"",
+			binariesVolumeMountPath,
+			binariesAppletsDir,
+			shell,
+			binariesVolumeMountPath,
+		), "start.sh"}
+	}
 
-	startCommand := binariesVolumeMountPath + "/start.sh"
 	container := applyCoreV1.Container().
 		WithName(r.container.Name).
-		WithCommand(startCommand).
+		WithCommand(startCommand...).
 		WithLivenessProbe(nullProbe).
 		WithReadinessProbe(nullProbe).
 		WithStartupProbe(nullProbe).
 		WithVolumeMounts(volumeMounts...)
+	if r.devImage != "" {
+		container.WithImage(r.devImage)
+	}
+
+	securityContext, err := r.getContainerSecurityContext()
+	if err != nil {
+		return err
+	}
+	if securityContext != nil {
+		container.WithSecurityContext(securityContext)
+	}
 
 	r.ContainerConfig.ApplyTo(container)
+	r.prepareWritableMounts(container)
 
 	podSpec.WithContainers(container)
 
 	return nil
 }
 
-func (r *RemoteDevelopment) getNullProbeApplyConfiguration() *applyCoreV1.ProbeApplyConfiguration {
+// hasBinariesShell is only true for the released remote-binaries image of build.SSHServerVersion, which ships
+// the static shell; a full reference, a digest or a mirror may serve an older image, so the shell of the
+// image is used as before
+func (r *RemoteDevelopment) hasBinariesShell() bool {
+	return r.getSSHServerImage() == build.SSHServerImage+":"+build.SSHServerVersion
+}
+
+// getBinariesShell returns the static shell of the remote-binaries volume mounted at the path, if any
+func (r *RemoteDevelopment) getBinariesShell(binariesVolumeMountPath string) string {
+	if !r.hasBinariesShell() {
+		return ""
+	}
+
+	return binariesVolumeMountPath + "/" + binariesShell
+}
+
+func (r *RemoteDevelopment) getNullProbeApplyConfiguration(shell string) *applyCoreV1.ProbeApplyConfiguration {
+	command := []string{"true"}
+	if shell != "" {
+		command = []string{shell, "true"}
+	}
+
 	return applyCoreV1.Probe().
-		WithExec(applyCoreV1.ExecAction().WithCommand("true")).
+		WithExec(applyCoreV1.ExecAction().WithCommand(command...)).
 		WithPeriodSeconds(5)
 }
 
@@ -591,21 +516,52 @@ func (r *RemoteDevelopment) getPVCName() (string, error) {
 		return "", err
 	}
 
-	return fmt.Sprintf(PVCNameFormat, r.resourceType, resource.GetName()), nil
+	return fmt.Sprintf(PVCNameFormat, workload.GetLabel(resource), resource.GetName()), nil
 }
 
-func (r *RemoteDevelopment) ensureSecret() error {
+func (r *RemoteDevelopment) ensureSecret(ctx context.Context) error {
 	r.StartSpinner(" Setup k8s secret")
 	defer r.spinner.Stop()
 
-	sshPublicKeyData, err := os.ReadFile(r.sshPublicKeyPath)
+	secret, err := r.getSecretApplyConfiguration()
 	if err != nil {
 		return err
 	}
 
+	// the secret is shared by the sessions of the namespace
+	namespace, name := *secret.Namespace, *secret.Name
+	_, err = r.kubernetesClient.GetSecret(ctx, namespace, name)
+	if err != nil && !apiErrors.IsNotFound(err) {
+		return err
+	}
+	secretExisted := err == nil
+
+	if err := r.kubernetesClient.ApplySecret(ctx, secret); err != nil {
+		return err
+	}
+
+	if !secretExisted {
+		r.completeStep("secret", func(ctx context.Context) error {
+			return r.kubernetesClient.DeleteSecret(ctx, namespace, name)
+		})
+	}
+
+	return nil
+}
+
+func (r *RemoteDevelopment) getSecretApplyConfiguration() (*applyCoreV1.SecretApplyConfiguration, error) {
+	sshPublicKeyData, err := os.ReadFile(r.sshPublicKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.newSecretApplyConfiguration(sshPublicKeyData)
+}
+
+func (r *RemoteDevelopment) newSecretApplyConfiguration(sshPublicKeyData []byte) (*applyCoreV1.SecretApplyConfiguration, error) {
 	resource, err := r.getResource()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	namespace := resource.GetNamespace()
@@ -617,11 +573,10 @@ func (r *RemoteDevelopment) ensureSecret() error {
 	secretData := make(map[string][]byte)
 	secretData[SecretAuthorizedKeysKeyName] = sshPublicKeyData
 
-	secret := applyCoreV1.Secret(r.getSecretName(), namespace).WithLabels(labels).WithData(secretData)
-	return r.kubernetesClient.ApplySecret(secret)
+	return applyCoreV1.Secret(r.getSecretName(), namespace).WithLabels(labels).WithData(secretData), nil
 }
 
-func (r *RemoteDevelopment) deletePVC() error {
+func (r *RemoteDevelopment) deletePVC(ctx context.Context) error {
 	resource, err := r.getResource()
 	if err != nil {
 		return err
@@ -631,23 +586,23 @@ func (r *RemoteDevelopment) deletePVC() error {
 	if err != nil {
 		return err
 	}
-	return r.kubernetesClient.DeletePVC(resource.GetNamespace(), pvcName)
+	return r.kubernetesClient.DeletePVC(ctx, resource.GetNamespace(), pvcName)
 }
 
 func (r *RemoteDevelopment) getResourceSelector() (*apiMetaV1.LabelSelector, error) {
-	switch r.resourceType {
-	case Deployment:
-		return r.deployment.Spec.Selector, nil
-	case StatefulSet:
-		return r.statefulSet.Spec.Selector, nil
-	case DaemonSet:
-		return r.daemonSet.Spec.Selector, nil
-	default:
-		return nil, r.resourceTypeNotSupportedError()
+	if r.isJobResource() {
+		return r.getDevJobSelector()
 	}
+
+	resource, err := r.getResource()
+	if err != nil {
+		return nil, err
+	}
+
+	return resource.GetSelector()
 }
 
-func (r *RemoteDevelopment) waitPodReady() error {
+func (r *RemoteDevelopment) waitPodReady(ctx context.Context) error {
 	r.StartSpinner(" Waiting for pod to be ready")
 	defer r.StopSpinner()
 
@@ -661,83 +616,56 @@ func (r *RemoteDevelopment) waitPodReady() error {
 		return err
 	}
 
-	namespace := resource.GetNamespace()
-	labelSelector := apiMetaV1.LabelSelector{MatchLabels: resourceSelector.MatchLabels}
-	listOptions := apiMetaV1.ListOptions{
-		LabelSelector: labels.Set(labelSelector.MatchLabels).String(),
+	_, err = workload.WaitPod(ctx, r.kubernetesClient, resource.GetNamespace(), resourceSelector, r.waitTimeout, r.isRemoteDevPodReady)
+	if errors.Is(err, workload.ErrPodWaitTimeout) {
+		return fmt.Errorf("pod not ready")
 	}
 
-	startTimestamp := time.Now().Unix()
-	for {
-		time.Sleep(1 * time.Second)
-		podList, err := r.kubernetesClient.ListPods(namespace, listOptions)
-		if err != nil {
-			return err
-		}
-
-		for _, pod := range podList.Items {
-			if pod.DeletionTimestamp != nil || pod.Status.Phase != coreV1.PodRunning {
-				continue
-			}
+	return err
+}
 
-			for _, containerStatus := range pod.Status.ContainerStatuses {
-				if containerStatus.Name == r.container.Name && containerStatus.Ready {
-					return nil
-				}
-			}
-		}
+// isRemoteDevPod matches the running pods started for this session, on the selected node if any,
+// leaving out the pods of the previous template still terminating and the sibling replicas
+func (r *RemoteDevelopment) isRemoteDevPod(pod *coreV1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != coreV1.PodRunning {
+		return false
+	}
 
-		nowTimestamp := time.Now().Unix()
-		if nowTimestamp-startTimestamp >= r.waitTimeout {
-			break
-		}
+	if pod.GetAnnotations()[MetadataStartedAt] != strconv.FormatInt(r.startedAt, 10) {
+		return false
 	}
 
-	// timeout reached
-	return fmt.Errorf("pod not ready")
+	return r.nodeName == "" || pod.Spec.NodeName == r.nodeName
 }
 
-func (r *RemoteDevelopment) getResourceContainers() ([]coreV1.Container, error) {
-	switch r.resourceType {
-	case Deployment:
-		return k8sTools.GetDeploymentContainers(r.deployment), nil
-	case StatefulSet:
-		return k8sTools.GetStatefulSetContainers(r.statefulSet), nil
-	case DaemonSet:
-		return k8sTools.GetDaemonSetContainers(r.daemonSet), nil
-	default:
-		return []coreV1.Container{}, r.resourceTypeNotSupportedError()
+func (r *RemoteDevelopment) isRemoteDevPodReady(pod *coreV1.Pod) bool {
+	if !r.isRemoteDevPod(pod) {
+		return false
 	}
-}
 
-func (r *RemoteDevelopment) getResourceContainer(containerName string) (*coreV1.Container, error) {
-	switch r.resourceType {
-	case Deployment:
-		return k8sTools.GetDeploymentContainerByName(r.deployment, containerName)
-	case StatefulSet:
-		return k8sTools.GetStatefulSetContainerByName(r.statefulSet, containerName)
-	case DaemonSet:
-		return k8sTools.GetDaemonSetContainerByName(r.daemonSet, containerName)
-	default:
-		return nil, ErrNoResourceSelected
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name == r.container.Name && containerStatus.Ready {
+			return true
+		}
 	}
+
+	return false
 }
 
-func (r *RemoteDevelopment) getContainerIndex() (int, error) {
-	if r.container == nil {
-		return -1, fmt.Errorf("%w: %s", ErrContainerNotFound, "<nil>")
+func (r *RemoteDevelopment) getResourceContainers() ([]coreV1.Container, error) {
+	resource, err := r.getResource()
+	if err != nil {
+		return nil, err
 	}
 
+	return resource.GetContainers()
+}
+
+func (r *RemoteDevelopment) getResourceContainer(containerName string) (*coreV1.Container, error) {
 	containers, err := r.getResourceContainers()
 	if err != nil {
-		return -1, err
-	}
-
-	for i, container := range containers {
-		if container.Name == r.container.Name {
-			return i, nil
-		}
+		return nil, err
 	}
 
-	return -1, fmt.Errorf("%w: %s", ErrContainerNotFound, r.container.Name)
+	return k8sTools.FilterContainerByName(containers, containerName)
 }
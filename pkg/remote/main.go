@@ -1,13 +1,18 @@
 package remote
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
-	"os/signal"
+	"strconv"
+	"time"
 
-	"bunnyshell.com/dev/pkg/util"
+	"bunnyshell.com/dev/pkg/k8s/workload"
 )
 
+// rollbackTimeout bounds the rollback of a failed Up, which runs after a cancellation too
+const rollbackTimeout = 2 * time.Minute
+
 func (r *RemoteDevelopment) CanUp(forceRecreateResource bool) error {
 	resource, err := r.getResource()
 	if err != nil {
@@ -27,6 +32,12 @@ func (r *RemoteDevelopment) CanUp(forceRecreateResource bool) error {
 			if containerName, found := annotations[MetadataContainer]; found {
 				if containerName == r.container.Name {
 					r.shouldPrepareResource = forceRecreateResource
+					if !forceRecreateResource {
+						// the running pods were started by the active session
+						if err := r.useSessionStartedAt(annotations); err != nil {
+							return err
+						}
+					}
 
 					return nil
 				}
@@ -47,7 +58,52 @@ func (r *RemoteDevelopment) CanUp(forceRecreateResource bool) error {
 	return nil
 }
 
+func (r *RemoteDevelopment) useSessionStartedAt(annotations map[string]string) error {
+	startedAt, err := strconv.ParseInt(annotations[MetadataStartedAt], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	r.startedAt = startedAt
+	return nil
+}
+
+// Up starts the session; a failure undoes the completed steps in reverse order, even when the failure
+// is the cancellation of the context, unless they are kept for troubleshooting
 func (r *RemoteDevelopment) Up() error {
+	r.upSteps = nil
+	err := r.up(r.getContext())
+	if err == nil || len(r.upSteps) == 0 {
+		return err
+	}
+
+	if r.keepOnFailure {
+		fmt.Print("WARNING: the completed steps were kept, run \"remote down\" to clean them up.\n")
+		return err
+	}
+
+	if rollbackErr := r.rollbackUp(); rollbackErr != nil {
+		return errors.Join(err, fmt.Errorf("cannot roll back: %w", rollbackErr))
+	}
+
+	return err
+}
+
+func (r *RemoteDevelopment) up(ctx context.Context) error {
+	if err := r.checkPin(); err != nil {
+		return err
+	}
+
+	if r.clone {
+		if err := r.ensureClone(ctx); err != nil {
+			return err
+		}
+	} else if r.isPinnedNode() {
+		if err := r.ensureNodeClone(ctx); err != nil {
+			return err
+		}
+	}
+
 	if err := r.ensureSSHKeys(); err != nil {
 		return err
 	}
@@ -57,26 +113,30 @@ func (r *RemoteDevelopment) Up() error {
 	}
 
 	if r.shouldPrepareResource {
-		if err := r.ensureSecret(); err != nil {
+		if err := r.ensureSecret(ctx); err != nil {
 			return err
 		}
 
-		if err := r.ensurePVC(); err != nil {
+		if err := r.ensurePVC(ctx); err != nil {
 			return err
 		}
 
-		if err := r.prepareResource(); err != nil {
+		if r.isJobResource() {
+			if err := r.prepareJob(ctx); err != nil {
+				return err
+			}
+		} else if err := r.prepareResource(ctx); err != nil {
 			return err
 		}
 	} else {
 		fmt.Print("Skip re-preparing Pod\n")
 	}
 
-	if err := r.waitPodReady(); err != nil {
+	if err := r.waitPodReady(ctx); err != nil {
 		return err
 	}
 
-	if err := r.ensureRemoteSSHPortForward(); err != nil {
+	if err := r.ensureRemoteSSHPortForward(ctx); err != nil {
 		return err
 	}
 
@@ -88,31 +148,135 @@ func (r *RemoteDevelopment) Up() error {
 		return err
 	}
 
+	if err := r.startDriftWatch(ctx); err != nil {
+		return err
+	}
+
 	return r.startMutagenSession()
 }
 
 func (r *RemoteDevelopment) Down() error {
-	if err := r.restoreDeployment(); err != nil {
+	ctx := r.getContext()
+
+	if r.clone {
+		return r.downClone(ctx)
+	}
+
+	if r.isJobResource() {
+		return r.downJob(ctx)
+	}
+
+	resource, err := r.getResource()
+	if err != nil {
 		return err
 	}
 
-	if err := r.deletePVC(); err != nil {
+	if _, ok := resource.GetAnnotations()[MetadataPinnedNode]; ok {
+		return r.downNodeClone(ctx)
+	}
+
+	if err := r.releaseResource(ctx, resource); err != nil {
+		return err
+	}
+
+	if err := r.deletePVC(ctx); err != nil {
 		return err
 	}
 
 	return r.terminateMutagenDaemon()
 }
 
+// releaseResource restores the resource and the objects paused or pinned for the session
+func (r *RemoteDevelopment) releaseResource(ctx context.Context, resource workload.Workload) error {
+	if err := r.restorePatchedResource(ctx, resource); err != nil {
+		return err
+	}
+
+	if err := r.restoreAutoscalers(ctx, resource); err != nil {
+		return err
+	}
+
+	return r.resumeGitOpsOwners(ctx, resource)
+}
+
+// restorePatchedResource restores the resource and replaces the pod of a pinned ordinal, which the controller
+// does not roll back itself when the StatefulSet updates on delete
+func (r *RemoteDevelopment) restorePatchedResource(ctx context.Context, resource workload.Workload) error {
+	if err := r.restoreResource(ctx, resource); err != nil {
+		return err
+	}
+
+	ordinal, ok := resource.GetAnnotations()[MetadataPinnedOrdinal]
+	if !ok {
+		return nil
+	}
+
+	manifest, err := r.getSessionRestoreManifest(resource)
+	if err != nil {
+		return err
+	}
+
+	if isOnDelete, err := isOnDeleteManifest(manifest); err != nil || !isOnDelete {
+		return err
+	}
+
+	return r.replacePinnedPod(ctx, resource, ordinal)
+}
+
+func (r *RemoteDevelopment) downClone(ctx context.Context) error {
+	if err := r.useClone(ctx); err != nil {
+		return err
+	}
+
+	if err := r.deleteClone(ctx); err != nil {
+		return err
+	}
+
+	if err := r.deletePVC(ctx); err != nil {
+		return err
+	}
+
+	return r.terminateMutagenDaemon()
+}
+
+// releaseJob deletes the dev Job and releases the selected Job or CronJob
+func (r *RemoteDevelopment) releaseJob(ctx context.Context, resource workload.Workload) error {
+	if err := r.deleteDevJob(ctx, resource); err != nil {
+		return err
+	}
+
+	return r.releaseJobResource(ctx, resource)
+}
+
+func (r *RemoteDevelopment) downJob(ctx context.Context) error {
+	resource, err := r.getResource()
+	if err != nil {
+		return err
+	}
+
+	if err := r.releaseJob(ctx, resource); err != nil {
+		return err
+	}
+
+	if err := r.resumeGitOpsOwners(ctx, resource); err != nil {
+		return err
+	}
+
+	if err := r.deletePVC(ctx); err != nil {
+		return err
+	}
+
+	return r.terminateMutagenDaemon()
+}
+
+// Wait blocks until the session is closed, or closes it when the context is cancelled
 func (r *RemoteDevelopment) Wait() error {
-	// close channels on cli signal interrupt
-	signalTermination := make(chan os.Signal, 1)
-	signal.Notify(signalTermination, util.TerminationSignals...)
-	defer signal.Stop(signalTermination)
+	ctx := r.getContext()
 
 	select {
-	case sig := <-signalTermination:
+	case <-ctx.Done():
 		r.Close()
-		return fmt.Errorf("terminated by signal: %s", sig)
+		return context.Cause(ctx)
 	case <-r.stopChannel:
 		return nil
 	}
@@ -121,6 +285,8 @@ func (r *RemoteDevelopment) Wait() error {
 func (r *RemoteDevelopment) Close() {
 	r.terminateMutagenSession()
 
+	r.stopDriftWatch()
+
 	// close ssh tunnels
 	for i := range r.sshTunnels {
 		r.sshTunnels[i].Stop()
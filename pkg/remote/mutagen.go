@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bufio"
 	"compress/gzip"
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"errors"
@@ -116,6 +117,11 @@ func (r *RemoteDevelopment) startMutagenSession() error {
 		),
 	}
 
+	// a failed create might still leave the session behind
+	r.completeStep("mutagen session", func(context.Context) error {
+		return r.terminateMutagenSession()
+	})
+
 	mutagenCmd := exec.Command(mutagenBinPath, mutagenArgs...)
 
 	output, err := mutagenCmd.CombinedOutput()
@@ -0,0 +1,376 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"bunnyshell.com/dev/pkg/k8s/patch"
+	"bunnyshell.com/dev/pkg/k8s/workload"
+
+	appsV1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	applyAppsV1 "k8s.io/client-go/applyconfigurations/apps/v1"
+)
+
+const (
+	MetadataPinnedNode    = MetadataPrefix + "pinned-node"
+	MetadataPinnedOrdinal = MetadataPrefix + "pinned-ordinal"
+
+	// the field matched by the node affinity of DaemonSet pods
+	nodeNameField = "metadata.name"
+)
+
+var (
+	ErrPinNotSupported = fmt.Errorf("pinning is only supported for DaemonSets and StatefulSets")
+	ErrNoNodeSelected  = fmt.Errorf("no node selected")
+	ErrNoPods          = fmt.Errorf("no pods available")
+	ErrOrdinalNotLast  = fmt.Errorf("only the highest ordinal can be pinned, the partitioned rolling update replaces the ordinals above the pinned one too")
+)
+
+func (r *RemoteDevelopment) WithPin(pin bool) *RemoteDevelopment {
+	r.pin = pin
+	return r
+}
+
+func (r *RemoteDevelopment) WithOrdinal(ordinal int) *RemoteDevelopment {
+	r.ordinal = ordinal
+	return r
+}
+
+// isPinnedNode reports whether only the DaemonSet pod on the selected node is replaced, by a clone
+// scheduled on that node alone
+func (r *RemoteDevelopment) isPinnedNode() bool {
+	return r.pin && r.resourceType == DaemonSet
+}
+
+// isPinnedOrdinal reports whether only the StatefulSet pod of the selected ordinal is replaced,
+// leaving the other ordinals running
+func (r *RemoteDevelopment) isPinnedOrdinal() bool {
+	return r.pin && r.resourceType == StatefulSet
+}
+
+func (r *RemoteDevelopment) checkPin() error {
+	if !r.pin {
+		return nil
+	}
+
+	if r.clone {
+		return fmt.Errorf("%w with clones", ErrPinNotSupported)
+	}
+
+	if r.isPinnedNode() {
+		if r.nodeName == "" {
+			return ErrNoNodeSelected
+		}
+
+		return nil
+	}
+
+	if !r.isPinnedOrdinal() {
+		return ErrPinNotSupported
+	}
+
+	resource, err := r.getResource()
+	if err != nil {
+		return err
+	}
+
+	statefulSet, ok := resource.GetObject().(*appsV1.StatefulSet)
+	if !ok {
+		return r.resourceTypeNotSupportedError()
+	}
+
+	replicas := 1
+	if statefulSet.Spec.Replicas != nil {
+		replicas = int(*statefulSet.Spec.Replicas)
+	}
+
+	if r.ordinal < 0 || r.ordinal >= replicas {
+		return fmt.Errorf("ordinal %d out of the %d replicas of statefulset \"%s\"", r.ordinal, replicas, resource.GetName())
+	}
+
+	if r.ordinal != replicas-1 {
+		return fmt.Errorf("%w: %d", ErrOrdinalNotLast, replicas-1)
+	}
+
+	return nil
+}
+
+// SelectOrdinal picks the StatefulSet pod used for a pinned session, the highest ordinal
+func (r *RemoteDevelopment) SelectOrdinal() error {
+	if !r.isPinnedOrdinal() || r.ordinal >= 0 {
+		return nil
+	}
+
+	resource, err := r.getResource()
+	if err != nil {
+		return err
+	}
+
+	statefulSet, ok := resource.GetObject().(*appsV1.StatefulSet)
+	if !ok {
+		return r.resourceTypeNotSupportedError()
+	}
+
+	replicas := 1
+	if statefulSet.Spec.Replicas != nil {
+		replicas = int(*statefulSet.Spec.Replicas)
+	}
+
+	if replicas == 0 {
+		return ErrNoPods
+	}
+
+	r.WithOrdinal(replicas - 1)
+	return nil
+}
+
+// pinOrdinalPatch keeps the replicas and partitions the rolling update at the ordinal, so the controller
+// only replaces its pod with the dev template
+func (r *RemoteDevelopment) pinOrdinalPatch(resourcePatch patch.Resource, annotations map[string]string) error {
+	statefulSetPatch, ok := resourcePatch.(*patch.StatefulSetPatchConfiguration)
+	if !ok {
+		return r.resourceTypeNotSupportedError()
+	}
+
+	updateStrategy := appsV1.RollingUpdateStatefulSetStrategyType
+	statefulSetPatch.Spec.Replicas = nil
+	statefulSetPatch.Spec.UpdateStrategy = &patch.StatefulSetStrategyPatchConfiguration{
+		Type:          &updateStrategy,
+		RollingUpdate: applyAppsV1.RollingUpdateStatefulSetStrategy().WithPartition(int32(r.ordinal)),
+	}
+
+	annotations[MetadataPinnedOrdinal] = strconv.Itoa(r.ordinal)
+
+	return nil
+}
+
+func getPinnedPodName(resource Resource, ordinal string) string {
+	return fmt.Sprintf("%s-%s", resource.GetName(), ordinal)
+}
+
+// replacePinnedPod deletes the pod of the pinned ordinal, recreated from the current template
+func (r *RemoteDevelopment) replacePinnedPod(ctx context.Context, resource Resource, ordinal string) error {
+	return r.kubernetesClient.DeletePod(ctx, resource.GetNamespace(), getPinnedPodName(resource, ordinal))
+}
+
+// isOnDeleteManifest reports whether the restored StatefulSet leaves the pinned pod to be replaced by hand,
+// otherwise its controller rolls the pod back
+func isOnDeleteManifest(manifest string) (bool, error) {
+	statefulSet := appsV1.StatefulSet{}
+	if err := json.Unmarshal([]byte(manifest), &statefulSet); err != nil {
+		return false, err
+	}
+
+	return statefulSet.Spec.UpdateStrategy.Type == appsV1.OnDeleteStatefulSetStrategyType, nil
+}
+
+// getPinnedReplicas returns the replicas autoscalers are pinned to during the session
+func (r *RemoteDevelopment) getPinnedReplicas(resource workload.Workload) int32 {
+	if !r.isPinnedOrdinal() {
+		return 1
+	}
+
+	statefulSet, ok := resource.GetObject().(*appsV1.StatefulSet)
+	if !ok || statefulSet.Spec.Replicas == nil {
+		return 1
+	}
+
+	return *statefulSet.Spec.Replicas
+}
+
+// ensureNodeClone excludes the selected node from the DaemonSet, without replacing the pods of the other
+// nodes, and makes a clone scheduled on that node alone the remote-dev target
+func (r *RemoteDevelopment) ensureNodeClone(ctx context.Context) error {
+	r.StartSpinner(" Pin resource to the selected node")
+	defer r.StopSpinner()
+
+	resource, err := r.getResource()
+	if err != nil {
+		return err
+	}
+
+	daemonSet, ok := resource.GetObject().(*appsV1.DaemonSet)
+	if !ok {
+		return r.resourceTypeNotSupportedError()
+	}
+
+	// the template of a pinned DaemonSet already excludes the node
+	if pinnedNode, ok := resource.GetAnnotations()[MetadataPinnedNode]; ok {
+		if pinnedNode != r.nodeName {
+			return fmt.Errorf("%s \"%s\" already pinned to node %s", workload.GetLabel(resource), resource.GetName(), pinnedNode)
+		}
+
+		return r.useClone(ctx)
+	}
+
+	annotations := map[string]string{
+		MetadataPinnedNode: r.nodeName,
+	}
+	if _, ok := resource.GetAnnotations()[MetadataRollback]; !ok {
+		rollbackSnapshot, err := resource.GetSnapshot()
+		if err != nil {
+			return err
+		}
+
+		annotations[MetadataRollback] = rollbackSnapshot
+	}
+
+	// OnDelete keeps the pods of the other nodes, while the pod of the excluded node is still removed
+	resourcePatch := map[string]any{
+		"metadata": map[string]any{
+			"annotations": annotations,
+		},
+		"spec": map[string]any{
+			"updateStrategy": map[string]any{
+				"type":          appsV1.OnDeleteDaemonSetStrategyType,
+				"rollingUpdate": nil,
+			},
+			"template": map[string]any{
+				"spec": map[string]any{
+					"affinity": map[string]any{
+						"nodeAffinity": map[string]any{
+							"requiredDuringSchedulingIgnoredDuringExecution": map[string]any{
+								"nodeSelectorTerms": withNodeRequirement(daemonSet.Spec.Template.Spec.Affinity, coreV1.NodeSelectorOpNotIn, r.nodeName),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	data, err := json.Marshal(resourcePatch)
+	if err != nil {
+		return err
+	}
+
+	if err := resource.Patch(ctx, data); err != nil {
+		return err
+	}
+	r.completeStep("pin node", func(ctx context.Context) error {
+		live, err := resource.Get(ctx)
+		if err != nil {
+			return err
+		}
+
+		return r.restoreResource(ctx, live)
+	})
+
+	if err := r.recordPinnedNodeGeneration(ctx, resource); err != nil {
+		return err
+	}
+
+	cloneName, err := r.getCloneName(resource)
+	if err != nil {
+		return err
+	}
+
+	objectMeta := r.getCloneObjectMeta(resource, cloneName)
+	clone, err := resource.Clone(ctx, objectMeta, func(_ workload.Workload, selector *apiMetaV1.LabelSelector, podTemplate *coreV1.PodTemplateSpec) (*apiMetaV1.LabelSelector, error) {
+		// the labels matched by the DaemonSet selector are dropped, unless the clone takes over the traffic
+		cloneSelector, podLabels := r.getCloneSelector(selector, podTemplate.Labels, cloneName)
+		podTemplate.Labels = podLabels
+
+		terms := withNodeRequirement(podTemplate.Spec.Affinity, coreV1.NodeSelectorOpIn, r.nodeName)
+		if podTemplate.Spec.Affinity == nil {
+			podTemplate.Spec.Affinity = &coreV1.Affinity{}
+		}
+		if podTemplate.Spec.Affinity.NodeAffinity == nil {
+			podTemplate.Spec.Affinity.NodeAffinity = &coreV1.NodeAffinity{}
+		}
+		podTemplate.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &coreV1.NodeSelector{
+			NodeSelectorTerms: terms,
+		}
+
+		return cloneSelector, nil
+	})
+	if err != nil {
+		return err
+	}
+	r.completeStep("node clone", clone.Delete)
+
+	r.WithWorkload(clone)
+	return nil
+}
+
+// recordPinnedNodeGeneration stores the generation of the pinned DaemonSet, the session state recorded
+// for the clone does not tell whether the DaemonSet was updated upstream
+func (r *RemoteDevelopment) recordPinnedNodeGeneration(ctx context.Context, resource workload.Workload) error {
+	pinned, err := resource.Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{
+				MetadataGeneration: strconv.FormatInt(pinned.GetGeneration(), 10),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// metadata changes do not bump the generation
+	r.pinnedNodeGeneration = pinned.GetGeneration()
+
+	return resource.Patch(ctx, data)
+}
+
+// withNodeRequirement adds the node requirement to every node selector term, since the terms are ORed
+func withNodeRequirement(affinity *coreV1.Affinity, operator coreV1.NodeSelectorOperator, nodeName string) []coreV1.NodeSelectorTerm {
+	requirement := coreV1.NodeSelectorRequirement{
+		Key:      nodeNameField,
+		Operator: operator,
+		Values:   []string{nodeName},
+	}
+
+	terms := []coreV1.NodeSelectorTerm{}
+	if affinity != nil && affinity.NodeAffinity != nil && affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			term := *term.DeepCopy()
+			term.MatchFields = append(term.MatchFields, requirement)
+			terms = append(terms, term)
+		}
+	}
+
+	if len(terms) == 0 {
+		terms = append(terms, coreV1.NodeSelectorTerm{
+			MatchFields: []coreV1.NodeSelectorRequirement{requirement},
+		})
+	}
+
+	return terms
+}
+
+// downNodeClone deletes the node clone and restores the DaemonSet, rescheduling its pod on the node
+func (r *RemoteDevelopment) downNodeClone(ctx context.Context) error {
+	resource, err := r.getResource()
+	if err != nil {
+		return err
+	}
+
+	if err := r.useClone(ctx); err != nil {
+		return err
+	}
+
+	if err := r.deleteClone(ctx); err != nil {
+		return err
+	}
+
+	if err := r.deletePVC(ctx); err != nil {
+		return err
+	}
+
+	r.WithWorkload(resource)
+	if err := r.restoreResource(ctx, resource); err != nil {
+		return err
+	}
+
+	return r.terminateMutagenDaemon()
+}
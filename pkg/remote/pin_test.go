@@ -0,0 +1,60 @@
+package remote
+
+import (
+	"reflect"
+	"testing"
+
+	"bunnyshell.com/dev/pkg/k8s/patch"
+	"bunnyshell.com/dev/pkg/k8s/workload"
+
+	appsV1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+)
+
+func TestWithNodeRequirement(t *testing.T) {
+	requirement := coreV1.NodeSelectorRequirement{Key: nodeNameField, Operator: coreV1.NodeSelectorOpNotIn, Values: []string{"node-1"}}
+	zone := coreV1.NodeSelectorRequirement{Key: "topology.kubernetes.io/zone", Operator: coreV1.NodeSelectorOpIn, Values: []string{"a"}}
+
+	expected := []coreV1.NodeSelectorTerm{{MatchFields: []coreV1.NodeSelectorRequirement{requirement}}}
+	if actual := withNodeRequirement(nil, coreV1.NodeSelectorOpNotIn, "node-1"); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	// the requirement is added to every ORed term, leaving the affinity of the workload untouched
+	affinity := &coreV1.Affinity{NodeAffinity: &coreV1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &coreV1.NodeSelector{NodeSelectorTerms: []coreV1.NodeSelectorTerm{
+			{MatchExpressions: []coreV1.NodeSelectorRequirement{zone}},
+			{},
+		}},
+	}}
+
+	expected = []coreV1.NodeSelectorTerm{
+		{MatchExpressions: []coreV1.NodeSelectorRequirement{zone}, MatchFields: []coreV1.NodeSelectorRequirement{requirement}},
+		{MatchFields: []coreV1.NodeSelectorRequirement{requirement}},
+	}
+	if actual := withNodeRequirement(affinity, coreV1.NodeSelectorOpNotIn, "node-1"); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	if terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms; len(terms[0].MatchFields) > 0 {
+		t.Errorf("affinity of the workload modified: %v", terms)
+	}
+}
+
+func TestPinOrdinalPatch(t *testing.T) {
+	r := NewRemoteDevelopment().WithOrdinal(2)
+	resourcePatch, err := workload.NewStatefulSet(nil, &appsV1.StatefulSet{}).GetPatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	annotations := map[string]string{}
+	if err := r.pinOrdinalPatch(resourcePatch, annotations); err != nil {
+		t.Fatal(err)
+	}
+
+	updateStrategy := resourcePatch.(*patch.StatefulSetPatchConfiguration).Spec.UpdateStrategy
+	if *updateStrategy.Type != appsV1.RollingUpdateStatefulSetStrategyType || *updateStrategy.RollingUpdate.Partition != 2 || annotations[MetadataPinnedOrdinal] != "2" {
+		t.Errorf("expected the rolling update partitioned at ordinal 2, got %+v, %v", updateStrategy, annotations)
+	}
+}
@@ -1,14 +1,14 @@
 package remote
 
 import (
+	"context"
 	"fmt"
 
 	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/k8s/workload"
 	"bunnyshell.com/dev/pkg/ssh"
 
 	coreV1 "k8s.io/api/core/v1"
-	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 )
 
 const (
@@ -16,21 +16,25 @@ const (
 	SSHPortForwardRemotePort = 2222
 )
 
-func (r *RemoteDevelopment) ensureRemoteSSHPortForward() error {
+func (r *RemoteDevelopment) ensureRemoteSSHPortForward(ctx context.Context) error {
 	r.StartSpinner(" Start Remote SSH Port Forward")
 	defer r.spinner.Stop()
 
-	remoteDevPod, err := r.getRemoteDevPod()
+	remoteDevPod, err := r.getRemoteDevPod(ctx)
 	if err != nil {
 		return err
 	}
 
 	r.sshPortForwardOptions = k8s.NewPortForwardOptions(SSHPortForwardInterface, SSHPortForwardRemotePort, 0)
-	forwarder, err := r.kubernetesClient.PortForward(remoteDevPod, r.sshPortForwardOptions)
+	forwarder, err := r.kubernetesClient.PortForward(ctx, remoteDevPod, r.sshPortForwardOptions)
 	if err != nil {
 		return err
 	}
 	r.sshPortForwarder = forwarder
+	r.completeStep("port-forward", func(context.Context) error {
+		forwarder.Close()
+		return nil
+	})
 
 	return nil
 }
@@ -46,12 +50,18 @@ func (r *RemoteDevelopment) startSSHTunnels() error {
 		if err := r.sshTunnels[i].Start(); err != nil {
 			return err
 		}
+
+		tunnel := r.sshTunnels[i]
+		r.completeStep("ssh tunnel", func(context.Context) error {
+			tunnel.Stop()
+			return nil
+		})
 	}
 
 	return nil
 }
 
-func (r *RemoteDevelopment) getRemoteDevPod() (*coreV1.Pod, error) {
+func (r *RemoteDevelopment) getRemoteDevPod(ctx context.Context) (*coreV1.Pod, error) {
 	resource, err := r.getResource()
 	if err != nil {
 		return nil, err
@@ -62,21 +72,13 @@ func (r *RemoteDevelopment) getRemoteDevPod() (*coreV1.Pod, error) {
 		return nil, err
 	}
 
-	namespace := resource.GetNamespace()
-	labelSelector := apiMetaV1.LabelSelector{MatchLabels: resourceSelector.MatchLabels}
-	listOptions := apiMetaV1.ListOptions{
-		LabelSelector: labels.Set(labelSelector.MatchLabels).String(),
-	}
-
-	podList, err := r.kubernetesClient.ListPods(namespace, listOptions)
+	pod, err := workload.FindPod(ctx, r.kubernetesClient, resource.GetNamespace(), resourceSelector, r.isRemoteDevPod)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, pod := range podList.Items {
-		if pod.DeletionTimestamp == nil && pod.Status.Phase == coreV1.PodRunning {
-			return &pod, nil
-		}
+	if pod != nil {
+		return pod, nil
 	}
 
 	return nil, fmt.Errorf("pod not found for component %v", resource.GetName())
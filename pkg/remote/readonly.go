@@ -0,0 +1,77 @@
+package remote
+
+import (
+	applyCoreV1 "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+const (
+	VolumeNameHome = "remote-dev-home"
+	VolumeNameTmp  = "remote-dev-tmp"
+	VolumeNameRun  = "remote-dev-run"
+
+	// home of the session user when the root filesystem is read-only, e.g. for the mutagen agent
+	homeMountPath = "/opt/bunnyshell/home"
+)
+
+type writableMount struct {
+	volumeName string
+	mountPath  string
+}
+
+// writableMounts are the locations written by start.sh and the SSH server
+var writableMounts = []writableMount{
+	{volumeName: VolumeNameHome, mountPath: homeMountPath},
+	{volumeName: VolumeNameTmp, mountPath: "/tmp"},
+	{volumeName: VolumeNameRun, mountPath: "/run"},
+}
+
+func (r *RemoteDevelopment) isReadOnlyRootFilesystem() bool {
+	securityContext := r.container.SecurityContext
+
+	return securityContext != nil && securityContext.ReadOnlyRootFilesystem != nil && *securityContext.ReadOnlyRootFilesystem
+}
+
+// getWritableMounts returns the emptyDir mounts needed by a read-only root filesystem, skipping the paths
+// the container already mounts
+func (r *RemoteDevelopment) getWritableMounts() []writableMount {
+	if !r.isReadOnlyRootFilesystem() {
+		return nil
+	}
+
+	mounts := []writableMount{}
+	for _, mount := range writableMounts {
+		mounted := false
+		for _, volumeMount := range r.container.VolumeMounts {
+			if volumeMount.MountPath == mount.mountPath {
+				mounted = true
+				break
+			}
+		}
+
+		if !mounted {
+			mounts = append(mounts, mount)
+		}
+	}
+
+	return mounts
+}
+
+func (r *RemoteDevelopment) getWritableVolumes() []*applyCoreV1.VolumeApplyConfiguration {
+	volumes := []*applyCoreV1.VolumeApplyConfiguration{}
+	for _, mount := range r.getWritableMounts() {
+		volumes = append(volumes, applyCoreV1.Volume().WithName(mount.volumeName).WithEmptyDir(applyCoreV1.EmptyDirVolumeSource()))
+	}
+
+	return volumes
+}
+
+// prepareWritableMounts mounts the writable locations in the dev container, moving the home to a writable one
+func (r *RemoteDevelopment) prepareWritableMounts(container *applyCoreV1.ContainerApplyConfiguration) {
+	for _, mount := range r.getWritableMounts() {
+		container.WithVolumeMounts(applyCoreV1.VolumeMount().WithName(mount.volumeName).WithMountPath(mount.mountPath))
+
+		if mount.volumeName == VolumeNameHome && !r.ContainerConfig.Environ.Has("HOME") {
+			container.WithEnv(applyCoreV1.EnvVar().WithName("HOME").WithValue(homeMountPath))
+		}
+	}
+}
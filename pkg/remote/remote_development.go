@@ -1,12 +1,15 @@
 package remote
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strconv"
 	"time"
 
+	"bunnyshell.com/dev/pkg/config"
 	"bunnyshell.com/dev/pkg/k8s"
+	"bunnyshell.com/dev/pkg/k8s/workload"
 	mutagenConfig "bunnyshell.com/dev/pkg/mutagen/config"
 	"bunnyshell.com/dev/pkg/remote/container"
 	"bunnyshell.com/dev/pkg/ssh"
@@ -14,17 +17,23 @@ import (
 
 	"github.com/briandowns/spinner"
 	appsV1 "k8s.io/api/apps/v1"
+	batchV1 "k8s.io/api/batch/v1"
 	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/tools/portforward"
 )
 
-// +enum
-type ResourceType string
+type ResourceType = workload.ResourceType
 
 const (
-	Deployment  ResourceType = "deployment"
-	StatefulSet ResourceType = "statefulset"
-	DaemonSet   ResourceType = "daemonset"
+	Deployment  = workload.Deployment
+	StatefulSet = workload.StatefulSet
+	DaemonSet   = workload.DaemonSet
+	Job         = workload.Job
+	CronJob     = workload.CronJob
+
+	// CustomResource is any workload kind mapped in the configuration
+	CustomResource = workload.CustomResource
 )
 
 type RemoteDevelopment struct {
@@ -46,17 +55,52 @@ type RemoteDevelopment struct {
 
 	namespace    *coreV1.Namespace
 	resourceType ResourceType
-	deployment   *appsV1.Deployment
-	statefulSet  *appsV1.StatefulSet
-	daemonSet    *appsV1.DaemonSet
+	workload     workload.Workload
 	container    *coreV1.Container
 
+	// node of the DaemonSet pod used for the session
+	nodeName string
+	// only replace the pod of the selected node or StatefulSet ordinal
+	pin     bool
+	ordinal int
+
+	// workload kinds mapped in the configuration
+	customKinds []config.Workload
+
+	// image of the dev container, the workload image when empty
+	devImage string
+	// helper images pulled from a mirror or pinned, and the secrets to pull them
+	imageRegistry       string
+	binariesImage       string
+	binariesImageDigest string
+	imagePullSecrets    []string
+
 	syncMode       mutagenConfig.Mode
 	localSyncPath  string
 	remoteSyncPath string
 
 	shouldPrepareResource bool
 
+	// steps completed by Up, undone on failure unless kept for troubleshooting
+	upSteps       []upStep
+	keepOnFailure bool
+
+	restoreStrategy      RestoreStrategy
+	sessionGeneration    int64
+	pinnedNodeGeneration int64
+	driftWatcher         watch.Interface
+
+	pauseGitOps    bool
+	forceConflicts bool
+
+	dryRun DryRun
+
+	clone        bool
+	cloneTraffic bool
+	// the clone was created with the session pod template, so it is not applied again
+	cloneCreated bool
+
+	ctx         context.Context
 	stopChannel chan bool
 
 	startedAt   int64
@@ -71,6 +115,10 @@ func NewRemoteDevelopment() *RemoteDevelopment {
 
 		shouldPrepareResource: true,
 
+		ordinal: -1,
+
+		customKinds: config.DefaultWorkloads,
+
 		stopChannel: make(chan bool),
 		spinner:     util.MakeSpinner(" Remote Development"),
 		syncMode:    mutagenConfig.TwoWayResolved,
@@ -100,8 +148,15 @@ func (r *RemoteDevelopment) WithSSH(sshPrivateKeyPath, sshPublicKeyPath string)
 	return r
 }
 
+// WithKubernetesClient loads the kubeconfig file alone
+//
+// Deprecated: use WithKubeConfigOptions
 func (r *RemoteDevelopment) WithKubernetesClient(kubeConfigPath string) *RemoteDevelopment {
-	kubernetesClient, err := k8s.NewKubernetesClient(kubeConfigPath)
+	return r.WithKubeConfigOptions(&k8s.KubeConfigOptions{KubeConfigPath: kubeConfigPath})
+}
+
+func (r *RemoteDevelopment) WithKubeConfigOptions(kubeConfigOptions *k8s.KubeConfigOptions) *RemoteDevelopment {
+	kubernetesClient, err := k8s.NewKubernetesClientFromOptions(kubeConfigOptions)
 	if err != nil {
 		panic(err)
 	}
@@ -111,13 +166,27 @@ func (r *RemoteDevelopment) WithKubernetesClient(kubeConfigPath string) *RemoteD
 	return r
 }
 
+// WithContext cancels the session setup and ends the session when the context is done
+func (r *RemoteDevelopment) WithContext(ctx context.Context) *RemoteDevelopment {
+	r.ctx = ctx
+	return r
+}
+
+func (r *RemoteDevelopment) getContext() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+
+	return r.ctx
+}
+
 func (r *RemoteDevelopment) WithNamespace(namespace *coreV1.Namespace) *RemoteDevelopment {
 	r.namespace = namespace
 	return r
 }
 
 func (r *RemoteDevelopment) WithNamespaceName(namespaceName string) *RemoteDevelopment {
-	namespace, err := r.kubernetesClient.GetNamespace(namespaceName)
+	namespace, err := r.kubernetesClient.GetNamespace(r.getContext(), namespaceName)
 	if err != nil {
 		panic(err)
 	}
@@ -139,85 +208,72 @@ func (r *RemoteDevelopment) WithResourceType(resourceType ResourceType) *RemoteD
 	return r
 }
 
-func (r *RemoteDevelopment) WithDeployment(deployment *appsV1.Deployment) *RemoteDevelopment {
+func (r *RemoteDevelopment) WithWorkload(resource workload.Workload) *RemoteDevelopment {
 	if r.namespace == nil {
 		panic(ErrNoNamespaceSelected)
 	}
 
-	if r.namespace.GetName() != deployment.GetNamespace() {
+	if r.namespace.GetName() != resource.GetNamespace() {
 		panic(fmt.Errorf(
-			"the deployment's namespace(\"%s\") doesn't match the selected namespace \"%s\"",
-			deployment.GetNamespace(),
+			"the %s's namespace(\"%s\") doesn't match the selected namespace \"%s\"",
+			workload.GetLabel(resource),
+			resource.GetNamespace(),
 			r.namespace.GetName(),
 		))
 	}
 
-	r.WithResourceType(Deployment)
-	r.deployment = deployment
+	r.WithResourceType(resource.GetResourceType())
+	r.workload = resource
 	return r
 }
 
-func (r *RemoteDevelopment) WithDeploymentName(deploymentName string) *RemoteDevelopment {
-	deployment, err := r.kubernetesClient.GetDeployment(r.namespace.GetName(), deploymentName)
+func (r *RemoteDevelopment) withResourceName(resourceType ResourceType, name string) *RemoteDevelopment {
+	resource, err := workload.Get(r.getContext(), r.kubernetesClient, resourceType, r.namespace.GetName(), name)
 	if err != nil {
 		panic(err)
 	}
 
-	return r.WithDeployment(deployment)
+	return r.WithWorkload(resource)
 }
 
-func (r *RemoteDevelopment) WithStatefulSet(statefulSet *appsV1.StatefulSet) *RemoteDevelopment {
-	if r.namespace == nil {
-		panic(ErrNoNamespaceSelected)
-	}
+func (r *RemoteDevelopment) WithDeployment(deployment *appsV1.Deployment) *RemoteDevelopment {
+	return r.WithWorkload(workload.NewDeployment(r.kubernetesClient, deployment))
+}
 
-	if r.namespace.GetName() != statefulSet.GetNamespace() {
-		panic(fmt.Errorf(
-			"the statefulset's namespace(\"%s\") doesn't match the selected namespace \"%s\"",
-			statefulSet.GetNamespace(),
-			r.namespace.GetName(),
-		))
-	}
+func (r *RemoteDevelopment) WithDeploymentName(deploymentName string) *RemoteDevelopment {
+	return r.withResourceName(Deployment, deploymentName)
+}
 
-	r.WithResourceType(StatefulSet)
-	r.statefulSet = statefulSet
-	return r
+func (r *RemoteDevelopment) WithStatefulSet(statefulSet *appsV1.StatefulSet) *RemoteDevelopment {
+	return r.WithWorkload(workload.NewStatefulSet(r.kubernetesClient, statefulSet))
 }
 
 func (r *RemoteDevelopment) WithStatefulSetName(name string) *RemoteDevelopment {
-	statefulSet, err := r.kubernetesClient.GetStatefulSet(r.namespace.GetName(), name)
-	if err != nil {
-		panic(err)
-	}
-
-	return r.WithStatefulSet(statefulSet)
+	return r.withResourceName(StatefulSet, name)
 }
 
 func (r *RemoteDevelopment) WithDaemonSet(daemonSet *appsV1.DaemonSet) *RemoteDevelopment {
-	if r.namespace == nil {
-		panic(ErrNoNamespaceSelected)
-	}
+	return r.WithWorkload(workload.NewDaemonSet(r.kubernetesClient, daemonSet))
+}
 
-	if r.namespace.GetName() != daemonSet.GetNamespace() {
-		panic(fmt.Errorf(
-			"the daemonset's namespace(\"%s\") doesn't match the selected namespace \"%s\"",
-			daemonSet.GetNamespace(),
-			r.namespace.GetName(),
-		))
-	}
+func (r *RemoteDevelopment) WithDaemonSetName(name string) *RemoteDevelopment {
+	return r.withResourceName(DaemonSet, name)
+}
 
-	r.WithResourceType(DaemonSet)
-	r.daemonSet = daemonSet
-	return r
+func (r *RemoteDevelopment) WithJob(job *batchV1.Job) *RemoteDevelopment {
+	return r.WithWorkload(workload.NewJob(r.kubernetesClient, job))
 }
 
-func (r *RemoteDevelopment) WithDaemonSetName(name string) *RemoteDevelopment {
-	daemonSet, err := r.kubernetesClient.GetDaemonSet(r.namespace.GetName(), name)
-	if err != nil {
-		panic(err)
-	}
+func (r *RemoteDevelopment) WithJobName(name string) *RemoteDevelopment {
+	return r.withResourceName(Job, name)
+}
 
-	return r.WithDaemonSet(daemonSet)
+func (r *RemoteDevelopment) WithCronJob(cronJob *batchV1.CronJob) *RemoteDevelopment {
+	return r.WithWorkload(workload.NewCronJob(r.kubernetesClient, cronJob))
+}
+
+func (r *RemoteDevelopment) WithCronJobName(name string) *RemoteDevelopment {
+	return r.withResourceName(CronJob, name)
 }
 
 func (r *RemoteDevelopment) WithContainer(container *coreV1.Container) *RemoteDevelopment {
@@ -238,53 +294,26 @@ func (r *RemoteDevelopment) WithContainerName(containerName string) *RemoteDevel
 	return r.WithContainer(container)
 }
 
-func (r *RemoteDevelopment) getResource() (Resource, error) {
-	switch r.resourceType {
-	case Deployment:
-		return r.deployment, nil
-	case StatefulSet:
-		return r.statefulSet, nil
-	case DaemonSet:
-		return r.daemonSet, nil
-	default:
-		return nil, r.resourceTypeNotSupportedError()
-	}
+func (r *RemoteDevelopment) WithNodeName(nodeName string) *RemoteDevelopment {
+	r.nodeName = nodeName
+	return r
 }
 
-func (r *RemoteDevelopment) getResourceType(resource Resource) (ResourceType, error) {
-	switch resource.(type) {
-	case *appsV1.Deployment:
-		return Deployment, nil
-	case *appsV1.StatefulSet:
-		return StatefulSet, nil
-	case *appsV1.DaemonSet:
-		return DaemonSet, nil
-	default:
-		return "", ErrInvalidResourceType
+func (r *RemoteDevelopment) getResource() (workload.Workload, error) {
+	if r.workload == nil {
+		return nil, ErrNoResourceSelected
 	}
+
+	return r.workload, nil
 }
 
 func (r *RemoteDevelopment) WithResource(resource Resource) *RemoteDevelopment {
-	resourceType, err := r.getResourceType(resource)
+	resourceWorkload, err := workload.New(r.kubernetesClient, resource, r.customKinds)
 	if err != nil {
 		panic(err)
 	}
 
-	switch resourceType {
-	case Deployment:
-		r.WithDeployment(resource.(*appsV1.Deployment))
-	case StatefulSet:
-		r.WithStatefulSet(resource.(*appsV1.StatefulSet))
-	case DaemonSet:
-		r.WithDaemonSet(resource.(*appsV1.DaemonSet))
-	default:
-		panic(fmt.Errorf(
-			"could not determine the resource Kind for resource type \"%s\"",
-			resourceType,
-		))
-	}
-
-	return r
+	return r.WithWorkload(resourceWorkload)
 }
 
 func (r *RemoteDevelopment) WithSSHTunnels(values ...*ssh.SSHTunnel) *RemoteDevelopment {
@@ -342,6 +371,31 @@ func (r *RemoteDevelopment) PrepareSSHTunnels(portMappings []string) error {
 	return nil
 }
 
+func (r *RemoteDevelopment) WithRestoreStrategy(restoreStrategy RestoreStrategy) *RemoteDevelopment {
+	r.restoreStrategy = restoreStrategy
+	return r
+}
+
+func (r *RemoteDevelopment) WithPauseGitOps(pauseGitOps bool) *RemoteDevelopment {
+	r.pauseGitOps = pauseGitOps
+	return r
+}
+
+func (r *RemoteDevelopment) WithForceConflicts(forceConflicts bool) *RemoteDevelopment {
+	r.forceConflicts = forceConflicts
+	return r
+}
+
+func (r *RemoteDevelopment) WithClone(clone bool) *RemoteDevelopment {
+	r.clone = clone
+	return r
+}
+
+func (r *RemoteDevelopment) WithCloneTraffic(cloneTraffic bool) *RemoteDevelopment {
+	r.cloneTraffic = cloneTraffic
+	return r
+}
+
 func (r *RemoteDevelopment) WithWaitTimeout(waitTimeout int64) *RemoteDevelopment {
 	r.waitTimeout = waitTimeout
 	return r
@@ -0,0 +1,98 @@
+package remote
+
+import (
+	k8sTools "bunnyshell.com/dev/pkg/k8s/tools"
+
+	coreV1 "k8s.io/api/core/v1"
+	applyCoreV1 "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+const (
+	PodSecurityEnforceLabel = k8sTools.PodSecurityEnforceLabel
+	PodSecurityRestricted   = k8sTools.PodSecurityRestricted
+	PodSecurityBaseline     = k8sTools.PodSecurityBaseline
+
+	DefaultRunAsUser = k8sTools.DefaultRunAsUser
+)
+
+func (r *RemoteDevelopment) isRestrictedNamespace() bool {
+	return k8sTools.IsRestrictedNamespace(r.namespace)
+}
+
+func (r *RemoteDevelopment) getRunAsIdentity() (*int64, *int64, error) {
+	resource, err := r.getResource()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	podSpec, err := resource.GetPodSpec()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	runAsUser, runAsGroup := k8sTools.GetRunAsIdentity(podSpec, r.container)
+
+	return runAsUser, runAsGroup, nil
+}
+
+// getInitSecurityContext runs the injected init containers as the target user
+func (r *RemoteDevelopment) getInitSecurityContext() (*applyCoreV1.SecurityContextApplyConfiguration, error) {
+	runAsUser, runAsGroup, err := r.getRunAsIdentity()
+	if err != nil {
+		return nil, err
+	}
+
+	return k8sTools.GetInjectedSecurityContext(runAsUser, runAsGroup, r.isRestrictedNamespace()), nil
+}
+
+// getContainerSecurityContext restricts the dev container in restricted namespaces, elsewhere the target one is kept
+func (r *RemoteDevelopment) getContainerSecurityContext() (*applyCoreV1.SecurityContextApplyConfiguration, error) {
+	if !r.isRestrictedNamespace() {
+		return nil, nil
+	}
+
+	return r.getInitSecurityContext()
+}
+
+// getPodSecurityContext makes the session volumes writable by the non-root containers of the pod through the fsGroup,
+// keeping the fsGroup of the target pod; root containers write the volumes anyway
+func (r *RemoteDevelopment) getPodSecurityContext() (*applyCoreV1.PodSecurityContextApplyConfiguration, error) {
+	resource, err := r.getResource()
+	if err != nil {
+		return nil, err
+	}
+
+	podSpec, err := resource.GetPodSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	podSecurityContext := podSpec.SecurityContext
+	if podSecurityContext != nil && podSecurityContext.FSGroup != nil {
+		return nil, nil
+	}
+
+	runAsUser, runAsGroup, err := r.getRunAsIdentity()
+	if err != nil {
+		return nil, err
+	}
+
+	// without a restricted namespace or a known user, the containers run as the image user, most likely root
+	if runAsUser == nil && !r.isRestrictedNamespace() {
+		return nil, nil
+	}
+
+	fsGroup := DefaultRunAsUser
+	if runAsGroup != nil {
+		fsGroup = *runAsGroup
+	} else if runAsUser != nil {
+		fsGroup = *runAsUser
+	}
+
+	securityContext := applyCoreV1.PodSecurityContext().WithFSGroup(fsGroup)
+	if podSecurityContext == nil || podSecurityContext.FSGroupChangePolicy == nil {
+		securityContext.WithFSGroupChangePolicy(coreV1.FSGroupChangeOnRootMismatch)
+	}
+
+	return securityContext, nil
+}
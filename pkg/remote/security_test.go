@@ -0,0 +1,38 @@
+package remote
+
+import (
+	"testing"
+
+	"bunnyshell.com/dev/pkg/k8s/workload"
+
+	appsV1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	apiMetaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetPodSecurityContext(t *testing.T) {
+	deployment := &appsV1.Deployment{}
+	deployment.Spec.Template.Spec.Containers = []coreV1.Container{{Name: "api"}}
+	r := &RemoteDevelopment{workload: workload.NewDeployment(nil, deployment), container: &deployment.Spec.Template.Spec.Containers[0]}
+
+	// the image user is most likely root, which writes the volumes anyway
+	if securityContext, err := r.getPodSecurityContext(); err != nil || securityContext != nil {
+		t.Errorf("expected no fsGroup, got %+v, %v", securityContext, err)
+	}
+
+	r.namespace = &coreV1.Namespace{ObjectMeta: apiMetaV1.ObjectMeta{Labels: map[string]string{PodSecurityEnforceLabel: PodSecurityRestricted}}}
+	if securityContext, _ := r.getPodSecurityContext(); securityContext == nil || *securityContext.FSGroup != DefaultRunAsUser {
+		t.Errorf("expected the default fsGroup, got %+v", securityContext)
+	}
+
+	if securityContext, _ := r.getContainerSecurityContext(); securityContext == nil || *securityContext.RunAsUser != DefaultRunAsUser || !*securityContext.RunAsNonRoot {
+		t.Errorf("expected a restricted dev container, got %+v", securityContext)
+	}
+
+	var runAsUser int64 = 1001
+	r.namespace = nil
+	r.container.SecurityContext = &coreV1.SecurityContext{RunAsUser: &runAsUser}
+	if securityContext, _ := r.getPodSecurityContext(); securityContext == nil || *securityContext.FSGroup != runAsUser {
+		t.Errorf("expected the fsGroup %d, got %+v", runAsUser, securityContext)
+	}
+}
@@ -1,6 +1,7 @@
 package remote
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -34,21 +35,40 @@ const (
 	SyncthingRemotePort      = 22000
 )
 
-func (r *RemoteDevelopment) ensureSSHKeys() error {
-	workspace, err := util.GetRemoteDevWorkspaceDir()
+// lookupSSHKeys uses the keys of the workspace when both exist, without creating anything
+func (r *RemoteDevelopment) lookupSSHKeys() (bool, error) {
+	workspaceDir, err := util.GetWorkspaceDir()
 	if err != nil {
-		return err
+		return false, err
 	}
 
+	workspace := filepath.Join(workspaceDir, util.RemoteDevDirname)
 	privatePemPath := filepath.Join(workspace, PrivateKeyFilename)
 	sshPublicKeyPath := filepath.Join(workspace, PublicKeyFilename)
 	_, err1 := os.Stat(privatePemPath)
 	_, err2 := os.Stat(sshPublicKeyPath)
-	if err1 == nil && err2 == nil {
-		r.WithSSH(privatePemPath, sshPublicKeyPath)
-		return nil
+	if err1 != nil || err2 != nil {
+		return false, nil
+	}
+
+	r.WithSSH(privatePemPath, sshPublicKeyPath)
+	return true, nil
+}
+
+func (r *RemoteDevelopment) ensureSSHKeys() error {
+	found, err := r.lookupSSHKeys()
+	if err != nil || found {
+		return err
+	}
+
+	workspace, err := util.GetRemoteDevWorkspaceDir()
+	if err != nil {
+		return err
 	}
 
+	privatePemPath := filepath.Join(workspace, PrivateKeyFilename)
+	sshPublicKeyPath := filepath.Join(workspace, PublicKeyFilename)
+
 	spinner := util.MakeSpinner(" Generate SSH RSA key...")
 	spinner.Start()
 	defer spinner.Stop()
@@ -121,10 +141,28 @@ func (r *RemoteDevelopment) ensureSSHConfigEntry() error {
 	if err := bunnyshellSSH.SaveConfig(config); err != nil {
 		return err
 	}
+	r.completeStep("ssh config entry", func(context.Context) error {
+		return r.removeSSHConfigEntry()
+	})
 
 	return bunnyshellSSH.IncludeBunnyshellConfig()
 }
 
+func (r *RemoteDevelopment) removeSSHConfigEntry() error {
+	config, err := bunnyshellSSH.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	hostname, err := r.getSSHHostname()
+	if err != nil {
+		return err
+	}
+	bunnyshellSSH.RemoveHost(config, hostname)
+
+	return bunnyshellSSH.SaveConfig(config)
+}
+
 func (r *RemoteDevelopment) getSSHHostname() (string, error) {
 	resource, err := r.getResource()
 	if err != nil {
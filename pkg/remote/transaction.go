@@ -0,0 +1,39 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// upStep is a completed step of Up, undone when a later step fails
+type upStep struct {
+	name string
+	undo func(ctx context.Context) error
+}
+
+func (r *RemoteDevelopment) WithKeepOnFailure(keepOnFailure bool) *RemoteDevelopment {
+	r.keepOnFailure = keepOnFailure
+	return r
+}
+
+func (r *RemoteDevelopment) completeStep(name string, undo func(ctx context.Context) error) {
+	r.upSteps = append(r.upSteps, upStep{name: name, undo: undo})
+}
+
+// rollbackUp undoes the completed steps in reverse order, with a context left uncancelled
+func (r *RemoteDevelopment) rollbackUp() error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.getContext()), rollbackTimeout)
+	defer cancel()
+
+	errs := []error{}
+	for i := len(r.upSteps) - 1; i >= 0; i-- {
+		step := r.upSteps[i]
+		if err := step.undo(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", step.name, err))
+		}
+	}
+	r.upSteps = nil
+
+	return errors.Join(errs...)
+}
@@ -67,12 +67,11 @@ func NewKV(paramName, paramValue string) *ssh_config.KV {
 }
 
 func IncludeBunnyshellConfig() error {
-	bunnyshellConfigFilePath, err := getBunnyshellConfigFilePath()
+	includeDirective, err := getIncludeDirective()
 	if err != nil {
 		return err
 	}
 
-	includeDirective := fmt.Sprintf("  Include %s", processConfigPathForInclude(bunnyshellConfigFilePath))
 	isIncluded, err := isBunnyshellConfigIncluded(includeDirective)
 	if err != nil {
 		return err
@@ -87,6 +86,25 @@ func IncludeBunnyshellConfig() error {
 	return nil
 }
 
+// IsBunnyshellConfigIncluded reports whether ~/.ssh/config includes the remote-dev hosts
+func IsBunnyshellConfigIncluded() (bool, error) {
+	includeDirective, err := getIncludeDirective()
+	if err != nil {
+		return false, err
+	}
+
+	return isBunnyshellConfigIncluded(includeDirective)
+}
+
+func getIncludeDirective() (string, error) {
+	bunnyshellConfigFilePath, err := getBunnyshellConfigFilePath()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("  Include %s", processConfigPathForInclude(bunnyshellConfigFilePath)), nil
+}
+
 func isBunnyshellConfigIncluded(includeDirective string) (bool, error) {
 	file, err := getConfigFile()
 	if err != nil && errors.Is(err, os.ErrNotExist) {
@@ -19,6 +19,16 @@ func Select(question string, items []string) (string, error) {
 	return answer, err
 }
 
+func Confirm(question string) (bool, error) {
+	answer := false
+	prompt := &survey.Confirm{
+		Message: question,
+	}
+	err := survey.AskOne(prompt, &answer)
+
+	return answer, err
+}
+
 func Ask(question, defaultInput string) (string, error) {
 	answer := ""
 	prompt := &survey.Input{
@@ -1,11 +1,51 @@
 package util
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/signal"
 	"syscall"
+	"time"
 )
 
 var TerminationSignals = []os.Signal{
 	syscall.SIGINT,
 	syscall.SIGTERM,
 }
+
+// NotifyTermination returns a context cancelled by the first termination signal, with the signal as its cause;
+// the next signal terminates the process as usual
+func NotifyTermination(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+
+	signalTermination := make(chan os.Signal, 1)
+	signal.Notify(signalTermination, TerminationSignals...)
+
+	go func() {
+		defer signal.Stop(signalTermination)
+
+		select {
+		case sig := <-signalTermination:
+			cancel(fmt.Errorf("terminated by signal: %s", sig))
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		cancel(context.Canceled)
+	}
+}
+
+// Sleep waits for the duration, or returns the cause of the context cancellation
+func Sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
@@ -29,7 +29,7 @@ func GetRemoteDevWorkspaceDir() (string, error) {
 	return path, nil
 }
 
-func getWorkspaceDir() (string, error) {
+func GetWorkspaceDir() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
@@ -43,7 +43,7 @@ func getWorkspaceDir() (string, error) {
 }
 
 func ensureRemoteDevWorkspaceDir() (string, error) {
-	workspaceDir, err := getWorkspaceDir()
+	workspaceDir, err := GetWorkspaceDir()
 	if err != nil {
 		return "", err
 	}